@@ -2,25 +2,40 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/hooks"
+	"github.com/ashish0kumar/taskly/internal/task"
 )
 
 var addCmd = &cobra.Command{
 	Use:   "add NAME",
 	Short: "Add a new task",
-	Long:  `Add a new task to your list. You can optionally assign it to a project.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Add a new task to your list. You can optionally assign it to a project,
+tag it and give it a due date: YYYY-MM-DD, today, tomorrow, or a number of
+days or weeks ahead like 3d or 2w. A task a pre-add hook puts in a status
+at its WIP limit is refused unless --force is given.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if dbConn == nil {
-			return fmt.Errorf("database connection not initialized")
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
 		}
 		taskName := args[0]
 		// Get project flag value
 		project, _ := cmd.Flags().GetString("project")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		dueSpec, _ := cmd.Flags().GetString("due")
+		due, err := task.ParseDue(dueSpec, time.Now())
+		if err != nil {
+			return db.Errorf(db.ErrInvalidInput, "%w", err)
+		}
 
-		// Use the exported Insert method from the db package via dbConn
-		newTask, err := dbConn.Insert(taskName, project)
+		force, _ := cmd.Flags().GetBool("force")
+		newTask, err := hooks.Insert(cmd.Context(), store, task.Task{Name: taskName, Project: project, Tags: tags, Due: due}, wipGuard(store, force))
 		if err != nil {
 			return fmt.Errorf("failed to add task: %w", err)
 		}
@@ -32,4 +47,7 @@ var addCmd = &cobra.Command{
 // init registers flags specific to the add command.
 func init() {
 	addCmd.Flags().StringP("project", "p", "", "Assign task to a specific project")
+	addCmd.Flags().StringSliceP("tag", "t", nil, "Tag the task; repeat or separate tags with commas")
+	addCmd.Flags().StringP("due", "d", "", "Due date: YYYY-MM-DD, today, tomorrow or a number of days or weeks ahead like 3d")
+	addCmd.Flags().BoolP("force", "f", false, "Add the task even if it exceeds the WIP limit")
 }
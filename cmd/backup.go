@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [PATH]",
+	Short: "Save a consistent copy of the tasks database",
+	Long: `Writes a copy of the tasks database to PATH, or to a new timestamped file
+in the backup directory (see 'taskly where --backups') when PATH is omitted.
+The copy is consistent even if another taskly process is writing.
+
+taskly also backs up automatically before schema migrations, imports and
+restores, keeping the ten most recent automatic backups. Restore any backup
+with 'taskly restore-backup FILE'.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tdb, err := sqliteDB(cmd)
+		if err != nil {
+			return err
+		}
+
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			if path, err = db.DefaultBackupPath(); err != nil {
+				return err
+			}
+		}
+
+		if err := tdb.BackupContext(cmd.Context(), path); err != nil {
+			return err
+		}
+		fmt.Printf("Backed up tasks to %s\n", path)
+		return nil
+	},
+}
+
+var restoreBackupCmd = &cobra.Command{
+	Use:   "restore-backup FILE",
+	Short: "Replace the tasks database with a backup",
+	Long: `Checks that FILE is an intact taskly database and replaces all current
+tasks with its contents. The current database is backed up automatically
+first, so a restore can itself be undone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tdb, err := sqliteDB(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := db.VerifyBackupContext(cmd.Context(), args[0]); err != nil {
+			return fmt.Errorf("refusing to restore '%s': %w", args[0], err)
+		}
+		saved, err := tdb.AutoBackupContext(cmd.Context(), "pre-restore")
+		if err != nil {
+			return fmt.Errorf("failed to back up current database, nothing was restored: %w", err)
+		}
+		if err := tdb.RestoreBackupContext(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Restored tasks from %s\n", args[0])
+		fmt.Printf("The previous database was saved to %s\n", saved)
+		return nil
+	},
+}
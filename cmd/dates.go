@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+// Named values accepted by --date-format. Any other value is used as a Go
+// time layout.
+const (
+	dateFormatRelative = "relative" // "3h ago", "yesterday"
+	dateFormatISO      = "iso"      // RFC 3339 in UTC
+	dateFormatLocal    = "local"    // Date and time in the local timezone
+	dateFormatDate     = "date"     // Date only in the local timezone
+)
+
+// validateDateFormat checks that format is a named format or a usable layout.
+func validateDateFormat(format string) error {
+	switch format {
+	case dateFormatRelative, dateFormatISO, dateFormatLocal, dateFormatDate:
+		return nil
+	}
+	// A layout without any reference-time elements formats to itself.
+	if format == "" || time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(format) == format {
+		return db.Errorf(db.ErrInvalidInput, "invalid date format %q: use relative, iso, local, date or a Go time layout like \"Jan 2 15:04\"", format)
+	}
+	return nil
+}
+
+// formatDate renders t according to format, relative to now where needed.
+// Timestamps are stored in UTC and converted to the local timezone here.
+func formatDate(t time.Time, format string, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch format {
+	case dateFormatRelative:
+		return relativeTime(t, now)
+	case dateFormatISO:
+		return t.UTC().Format(time.RFC3339)
+	case dateFormatLocal:
+		return t.Local().Format("2006-01-02 15:04")
+	case dateFormatDate:
+		return t.Local().Format("2006-01-02")
+	default:
+		return t.Local().Format(format)
+	}
+}
+
+// relativeTime describes t relative to now, e.g. "5m ago", "yesterday" or
+// "3 days ago", falling back to a plain date for anything older than a week.
+func relativeTime(t, now time.Time) string {
+	t, now = t.Local(), now.Local()
+	d := now.Sub(t)
+	if d < 0 {
+		return t.Format("2006-01-02 15:04")
+	}
+
+	// Whole calendar days between the two local dates.
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := now.Date()
+	days := int(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case days == 0 || d < 12*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%d days ago", days)
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// parseSince parses a look-back period such as "7d", "2w" or "36h" and
+// returns the point in time that far before now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		count, err := strconv.Atoi(s[:n-1])
+		if err == nil && count >= 0 {
+			days := count
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, db.Errorf(db.ErrInvalidInput, "invalid period %q: use a number of days, weeks or hours like 7d, 2w or 36h", s)
+	}
+	return now.Add(-d), nil
+}
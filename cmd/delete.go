@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
 )
 
 var deleteCmd = &cobra.Command{
@@ -13,20 +15,21 @@ var deleteCmd = &cobra.Command{
 	Long:  `Permanently removes a task from the database using its unique ID.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if dbConn == nil {
-			return fmt.Errorf("database connection not initialized")
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
 		}
 		idStr := args[0]
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			return fmt.Errorf("invalid ID %q: %w", idStr, err)
+			return db.Errorf(db.ErrInvalidInput, "invalid ID %q: %w", idStr, err)
 		}
 
 		// Get task details *before* deleting for a better confirmation message.
-		taskToDelete, getErr := dbConn.GetTask(uint(id))
+		taskToDelete, getErr := store.GetTaskContext(cmd.Context(), uint(id))
 
 		// Attempt to delete the task
-		err = dbConn.Delete(uint(id))
+		err = store.DeleteContext(cmd.Context(), uint(id))
 		if err != nil {
 			return fmt.Errorf("failed to delete task %d: %w", id, err)
 		}
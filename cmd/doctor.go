@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the tasks database for damage and inconsistencies",
+	Long: `Runs SQLite's integrity and foreign key checks, verifies that every task
+has a known status and a completion time that matches it, and reports the
+schema version. Nothing is changed unless a flag asks for it:
+
+  --repair  rebuild indexes and fix unknown statuses and completion times
+  --vacuum  rebuild the database file to reclaim unused space
+
+The database is backed up automatically before either is applied. Damage
+that --repair cannot fix should be recovered with 'taskly restore-backup'.
+Exits with an error if any problem remains.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tdb, err := sqliteDB(cmd)
+		if err != nil {
+			return err
+		}
+
+		repair, _ := cmd.Flags().GetBool("repair")
+		vacuum, _ := cmd.Flags().GetBool("vacuum")
+
+		report, err := tdb.CheckContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		printHealthReport(report)
+
+		if (repair && !report.OK()) || vacuum {
+			saved, err := tdb.AutoBackupContext(cmd.Context(), "pre-repair")
+			if err != nil {
+				return fmt.Errorf("failed to back up database, nothing was changed: %w", err)
+			}
+			fmt.Printf("\nBacked up the database to %s\n", saved)
+		}
+
+		if repair && !report.OK() {
+			actions, err := tdb.RepairContext(cmd.Context())
+			for _, a := range actions {
+				fmt.Printf("  repaired: %s\n", a)
+			}
+			if err != nil {
+				return err
+			}
+			if report, err = tdb.CheckContext(cmd.Context()); err != nil {
+				return err
+			}
+			fmt.Println()
+			printHealthReport(report)
+		}
+
+		if vacuum {
+			if err := tdb.VacuumContext(cmd.Context()); err != nil {
+				return err
+			}
+			fmt.Println("Vacuumed the database.")
+		}
+
+		if !report.OK() {
+			if repair {
+				return fmt.Errorf("some problems could not be repaired; consider 'taskly restore-backup'")
+			}
+			return fmt.Errorf("problems found; run 'taskly doctor --repair' to fix what can be fixed")
+		}
+		return nil
+	},
+}
+
+// printHealthReport prints the schema version and the outcome of each check.
+func printHealthReport(report db.HealthReport) {
+	fmt.Printf("Schema version: %d (latest %d)\n", report.SchemaVersion, report.LatestSchemaVersion)
+	for _, c := range report.Checks {
+		if c.OK() {
+			fmt.Printf("  ok    %s\n", c.Name)
+			continue
+		}
+		note := ""
+		if c.Repairable {
+			note = ", repairable"
+		}
+		fmt.Printf("  FAIL  %s (%d problem(s)%s)\n", c.Name, len(c.Problems), note)
+		for _, p := range c.Problems {
+			fmt.Printf("          %s\n", p)
+		}
+	}
+}
+
+// init registers flags specific to the doctor command.
+func init() {
+	doctorCmd.Flags().Bool("repair", false, "Fix the problems that can be fixed safely")
+	doctorCmd.Flags().Bool("vacuum", false, "Rebuild the database file to reclaim unused space")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+// Exit codes reported by taskly, so scripts can tell failures apart.
+const (
+	ExitOK           = 0   // Success
+	ExitError        = 1   // Any failure not listed below
+	ExitInvalidInput = 2   // Bad arguments, flags or input files
+	ExitNotFound     = 3   // The task or file does not exist
+	ExitConflict     = 4   // The change clashes with existing data or a WIP limit, or a hook refused it
+	ExitBusy         = 5   // Another process kept the database locked
+	ExitTimeout      = 124 // The --timeout deadline passed
+	ExitInterrupted  = 130 // Cancelled by Ctrl+C or SIGTERM
+)
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, db.ErrInvalidInput):
+		return ExitInvalidInput
+	case errors.Is(err, db.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, db.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, db.ErrBusy):
+		return ExitBusy
+	}
+	return ExitError
+}
+
+// usageError marks errors found by cobra while parsing the command line as
+// invalid input.
+func usageError(_ *cobra.Command, err error) error {
+	return db.Errorf(db.ErrInvalidInput, "%w", err)
+}
+
+// checkedArgs wraps an argument validator so its errors count as usage errors.
+func checkedArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError(cmd, err)
+		}
+		return nil
+	}
+}
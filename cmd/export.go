@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/exporter"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tasks as JSON, CSV, todo.txt, Markdown or iCalendar",
+	Long: `Writes tasks to standard output, or to a file with --output. Supported formats:
+
+  json      an array of task objects with RFC 3339 timestamps
+  csv       a CSV file with a header row; readable by 'taskly import'
+  todotxt   one todo.txt line per task; the project becomes a +project tag
+  markdown  a "- [ ]" / "- [x]" checklist with a section per project
+  ics       an iCalendar file with one VTODO per task
+
+The same --project, --hide-done, --done-since and --sort flags as 'list'
+select and order the exported tasks.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !isExportFormat(format) {
+			return db.Errorf(db.ErrInvalidInput, "unknown export format %q (valid: %s)", format, strings.Join(exporter.Formats(), ", "))
+		}
+
+		sortSpec, _ := cmd.Flags().GetString("sort")
+		sortKeys, err := db.ParseSortKeys(sortSpec)
+		if err != nil {
+			return err
+		}
+		opts, err := filterOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		opts.Sort = sortKeys
+
+		tasks, err := store.ListTasksContext(cmd.Context(), opts)
+		if err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		var out io.Writer = os.Stdout
+		if path, _ := cmd.Flags().GetString("output"); path != "" && path != "-" {
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create export file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err := exporter.Write(format, out, tasks); err != nil {
+			return err
+		}
+		if f, ok := out.(*os.File); ok && f != os.Stdout {
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write export file: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d task(s) to %s\n", len(tasks), f.Name())
+		}
+		return nil
+	},
+}
+
+// init registers flags specific to the export command.
+func init() {
+	addFilterFlags(exportCmd)
+	exportCmd.Flags().StringP("format", "f", "", fmt.Sprintf("Output format (%s)", strings.Join(exporter.Formats(), ", ")))
+	exportCmd.Flags().StringP("output", "o", "", "Write to this file instead of standard output")
+	exportCmd.Flags().String("sort", "", fmt.Sprintf("Sort by fields, e.g. status,created:desc (fields: %s)", strings.Join(db.SortFields(), ", ")))
+	exportCmd.MarkFlagRequired("format")
+}
+
+// isExportFormat reports whether format is one the exporter supports.
+func isExportFormat(format string) bool {
+	for _, f := range exporter.Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+// addFilterFlags registers the task filter flags shared by list, export and kanban.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("project", "p", "", "Only show tasks in this project")
+	cmd.Flags().String("tag", "", "Only show tasks with this tag")
+	cmd.Flags().Bool("hide-done", false, "Hide tasks that are done")
+	cmd.Flags().String("done-since", "", "Only show done tasks finished within this period, e.g. 7d, 2w, 36h")
+}
+
+// filterOptionsFromFlags builds list options from the flags registered by addFilterFlags.
+func filterOptionsFromFlags(cmd *cobra.Command) (db.ListOptions, error) {
+	var opts db.ListOptions
+	opts.Project, _ = cmd.Flags().GetString("project")
+	opts.HideDone, _ = cmd.Flags().GetBool("hide-done")
+	tag, _ := cmd.Flags().GetString("tag")
+	opts.Tag = strings.ToLower(strings.TrimSpace(tag))
+
+	if doneSince, _ := cmd.Flags().GetString("done-since"); doneSince != "" {
+		if opts.HideDone {
+			return opts, db.Errorf(db.ErrInvalidInput, "--hide-done and --done-since cannot be used together")
+		}
+		since, err := parseSince(doneSince, time.Now())
+		if err != nil {
+			return opts, err
+		}
+		opts.DoneSince = since
+	}
+	return opts, nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/importer"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import tasks from Taskwarrior, todo.txt, Todoist or CSV",
+	Long: `Imports tasks from a file ("-" reads standard input). Supported formats:
+
+  taskwarrior-json  output of 'task export'
+  todotxt           a todo.txt file; the first +project becomes the project
+  csv               a CSV file with a header row, e.g. from 'taskly export'
+  todoist-csv       a Todoist project exported as CSV
+
+Status, project, creation and completion dates are kept where the format
+provides them. Tasks whose name and project match an existing task are
+skipped unless --allow-duplicates is given. The whole import runs in one
+transaction: if any task fails, nothing is imported, and the SQLite
+database is backed up automatically beforehand. Use --dry-run to preview the result
+without changing anything.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		project, _ := cmd.Flags().GetString("project")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		allowDuplicates, _ := cmd.Flags().GetBool("allow-duplicates")
+
+		path := args[0]
+		var in io.Reader = os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("failed to open import file: %w", err)
+			}
+			defer f.Close()
+			in = f
+
+			// Todoist exports one project per file, named after the project.
+			if format == importer.FormatTodoistCSV && project == "" {
+				project = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			}
+		}
+
+		tasks, err := importer.Parse(format, in, importer.Options{Project: project})
+		if err != nil {
+			return db.Errorf(db.ErrInvalidInput, "failed to parse %s: %w", path, err)
+		}
+		if len(tasks) == 0 {
+			fmt.Println("No tasks found to import.")
+			return nil
+		}
+
+		// Task files are meant to be versioned, so only the database is backed up.
+		if tdb, ok := baseStore(store).(*db.TaskDB); ok && !dryRun {
+			if _, err := tdb.AutoBackupContext(cmd.Context(), "pre-import"); err != nil {
+				return fmt.Errorf("failed to back up database, nothing was imported: %w", err)
+			}
+		}
+
+		result, err := store.ImportTasksContext(cmd.Context(), tasks, db.ImportOptions{
+			AllowDuplicates: allowDuplicates,
+			DryRun:          dryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("import failed, nothing was imported: %w", err)
+		}
+
+		if dryRun {
+			fmt.Printf("Dry run: would import %d task(s) and skip %d duplicate(s).\n", len(result.Imported), len(result.Duplicates))
+			if len(result.Imported) > 0 {
+				opts := renderOptions{dateFormat: appConfig.DateFormat, now: time.Now()}
+				plain := !stdoutIsTerminal()
+				if !plain {
+					opts.width = terminalWidth()
+				}
+				if err := printTasks(result.Imported, listColumns, opts, plain); err != nil {
+					return err
+				}
+			}
+		} else {
+			fmt.Printf("Imported %d task(s), skipped %d duplicate(s).\n", len(result.Imported), len(result.Duplicates))
+		}
+		for _, t := range result.Duplicates {
+			fmt.Printf("  duplicate: %s", t.Name)
+			if t.Project != "" {
+				fmt.Printf(" (%s)", t.Project)
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+// init registers flags specific to the import command.
+func init() {
+	importCmd.Flags().StringP("format", "f", "", fmt.Sprintf("Input format (%s)", strings.Join(importer.Formats(), ", ")))
+	importCmd.Flags().StringP("project", "p", "", "Project for tasks that have none (Todoist defaults to the file name)")
+	importCmd.Flags().Bool("dry-run", false, "Show what would be imported without changing anything")
+	importCmd.Flags().Bool("allow-duplicates", false, "Import tasks even if the same name and project already exist")
+	importCmd.MarkFlagRequired("format")
+}
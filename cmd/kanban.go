@@ -3,77 +3,62 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/kancli"
 	"github.com/spf13/cobra"
 
-	"github.com/ashish0kumar/taskly/internal/task"
+	"github.com/ashish0kumar/taskly/internal/tui"
 )
 
 var kanbanCmd = &cobra.Command{
 	Use:   "kanban",
 	Short: "View tasks on an interactive Kanban board",
 	Long: `Displays tasks visually categorized by status (todo, in progress, done)
-on an interactive Kanban board. Use arrow keys to navigate, Enter/Space
-to potentially interact (depending on kancli features).`,
+on an interactive Kanban board. Use arrow keys to navigate between columns
+and tasks. Press n to create a task in the focused column, e to edit the
+selected task, d to delete it (with confirmation) and Enter to move it to
+the next column. Changes are saved to the database immediately, and the
+board refreshes itself when tasks are changed from another terminal.
+
+Use --project to show a single project's board, --tag to show only tasks
+with a tag (matched case-insensitively, as tags are stored in lower case),
+--hide-done to leave out finished tasks, or --done-since (e.g. 7d, 2w, 36h)
+to only show recently finished ones. Column titles show their task counts
+and the active filter.
+
+Columns with a WIP limit configured ("wip_limits" in the config file) show
+their count against the limit across all projects, are highlighted when
+over it, and refuse new or moved tasks once full.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if dbConn == nil {
-			return fmt.Errorf("database connection not initialized")
-		}
-
-		allTasks, err := dbConn.GetTasks() // Fetch all tasks once
+		store, err := storeFrom(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get tasks for kanban: %w", err)
+			return err
 		}
 
-		if len(allTasks) == 0 {
-			fmt.Println("No tasks found to display on the board.")
-			return nil
+		opts, err := filterOptionsFromFlags(cmd)
+		if err != nil {
+			return err
 		}
 
-		todosMap := []list.Item{}
-		iprMap := []list.Item{}
-		finishedMap := []list.Item{}
-
-		for i := range allTasks {
-			// Pass task value directly assuming task methods use value receivers
-			tsk := allTasks[i]
-			switch tsk.Status {
-			case task.Todo.String():
-				todosMap = append(todosMap, tsk)
-			case task.InProgress.String():
-				iprMap = append(iprMap, tsk)
-			case task.Done.String():
-				finishedMap = append(finishedMap, tsk)
-			}
+		board, err := tui.NewBoard(store, opts, appConfig.WIPLimits, runFrom(cmd).timeout)
+		if err != nil {
+			return fmt.Errorf("failed to set up kanban board: %w", err)
 		}
+		defer board.Close()
 
-		// Create Kanban columns using the status enum values from task package
-		todoCol := kancli.NewColumn(todosMap, task.Todo, true)
-		iprCol := kancli.NewColumn(iprMap, task.InProgress, false)
-		doneCol := kancli.NewColumn(finishedMap, task.Done, false)
-
-		// Note: Persisting changes (moving tasks between columns) requires
-		// more advanced integration or a custom Bubble Tea model.
-		// Kancli's default board might not automatically save changes back to the DB.
-
-		board := kancli.NewDefaultBoard([]kancli.Column{todoCol, iprCol, doneCol})
-
-		p := tea.NewProgram(board)
+		p := tea.NewProgram(board, tea.WithContext(cmd.Context()))
 
 		// Run the Bubble Tea program (blocking)
-		// Use p.Start() for non-blocking if needed elsewhere.
-		finalModel, err := p.Run()
-		if err != nil {
+		if _, err := p.Run(); err != nil {
 			return fmt.Errorf("kanban board error: %w", err)
 		}
 
-		// Placeholder for potential post-run processing if model state needs saving
-		_ = finalModel // Avoid unused variable error
-
 		fmt.Println("\nKanban board closed.")
 		return nil
 	},
 }
+
+// init registers flags specific to the kanban command.
+func init() {
+	addFilterFlags(kanbanCmd)
+}
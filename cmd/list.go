@@ -2,25 +2,123 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/spf13/cobra"
 
+	"github.com/ashish0kumar/taskly/internal/db"
 	"github.com/ashish0kumar/taskly/internal/task"
 )
 
+// listColumn describes one selectable column of the task table.
+type listColumn struct {
+	key    string
+	header string
+	value  func(t task.Task, opts renderOptions) string
+	fit    fitColumn // How the column behaves on narrow terminals
+}
+
+// renderOptions controls how tasks are turned into table cells.
+type renderOptions struct {
+	width      int       // Maximum table width in cells
+	wrap       bool      // Wrap long cells instead of truncating them
+	dateFormat string    // See formatDate
+	now        time.Time // Reference time for relative dates
+}
+
+// listColumns holds every column the list table can show, in default order.
+// On narrow terminals name and project are truncated first, then tags,
+// created, due, project and status are dropped in that order.
+var listColumns = []listColumn{
+	{"id", "ID", func(t task.Task, _ renderOptions) string { return fmt.Sprintf("%d", t.ID) }, fitColumn{priority: -1}},
+	{"name", "Name", func(t task.Task, _ renderOptions) string { return t.Name }, fitColumn{minWidth: 12, priority: -1}},
+	{"project", "Project", func(t task.Task, _ renderOptions) string { return t.Project }, fitColumn{minWidth: 8, priority: 3}},
+	{"tags", "Tags", func(t task.Task, _ renderOptions) string { return strings.Join(t.Tags, ", ") }, fitColumn{priority: 0}},
+	{"due", "Due", func(t task.Task, _ renderOptions) string { return task.FormatDue(t.Due) }, fitColumn{priority: 2}},
+	{"status", "Status", func(t task.Task, _ renderOptions) string { return t.Status }, fitColumn{priority: 4}},
+	{"created", "Created At", func(t task.Task, o renderOptions) string { return formatDate(t.Created, o.dateFormat, o.now) }, fitColumn{priority: 1}},
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all your tasks",
-	Long:  `Displays all tasks currently stored in the database, ordered by creation date.`,
-	Args:  cobra.NoArgs,
+	Long: `Displays all tasks currently stored in the database, ordered by status and
+then by manual rank (see 'taskly move').
+
+Use --sort to order by one or more fields, appending ":desc" to reverse a
+field, and --columns to choose which columns are shown:
+
+  taskly list --sort status,created:desc --columns id,name,status
+
+The table adapts to the terminal width: long names and projects are
+truncated (or wrapped with --wrap) and low-priority columns are hidden when
+space is tight. When output is not a terminal, a plain aligned layout
+without borders or colours is printed instead.
+
+Use --project, --tag, --hide-done or --done-since (e.g. 7d) to narrow the
+list.
+
+Use --group-by to print a titled table per project, status, due date or tag,
+each with its task count, instead of one flat table. Due dates are grouped
+into overdue, today, the next 7 days, later and none; a task with several
+tags appears under each of them.
+
+Dates are shown according to --date-format, which defaults to the
+"date_format" setting in the config file (see 'taskly where --config'):
+relative ("3h ago", "yesterday"), iso (RFC 3339, UTC), local (date and time
+in your timezone), date, or any Go time layout such as "Jan 2 15:04".`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if dbConn == nil {
-			return fmt.Errorf("database connection not initialized")
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		sortSpec, _ := cmd.Flags().GetString("sort")
+		sortKeys, err := db.ParseSortKeys(sortSpec)
+		if err != nil {
+			return err
 		}
 
-		tasks, err := dbConn.GetTasks()
+		columnsSpec, _ := cmd.Flags().GetString("columns")
+		columns, err := parseListColumns(columnsSpec)
+		if err != nil {
+			return err
+		}
+
+		dateFormat := appConfig.DateFormat
+		if cmd.Flags().Changed("date-format") {
+			dateFormat, _ = cmd.Flags().GetString("date-format")
+		}
+		if err := validateDateFormat(dateFormat); err != nil {
+			return err
+		}
+
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		groupBy = strings.ToLower(strings.TrimSpace(groupBy))
+		by, ok := groupings[groupBy]
+		if groupBy != "" {
+			if !ok {
+				return db.Errorf(db.ErrInvalidInput, "invalid group-by %q (valid: %s)", groupBy, strings.Join(groupByFields, ", "))
+			}
+			// Sort by the group first so groups come back in their natural order.
+			if by.sort != "" {
+				sortKeys = append([]db.SortKey{{Field: by.sort}}, sortKeys...)
+			}
+		}
+
+		listOpts, err := filterOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		listOpts.Sort = sortKeys
+
+		tasks, err := store.ListTasksContext(cmd.Context(), listOpts)
 		if err != nil {
 			return fmt.Errorf("failed to list tasks: %w", err)
 		}
@@ -30,31 +128,240 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
-		fmt.Println(setupTable(tasks).String())
+		opts := renderOptions{dateFormat: dateFormat, now: time.Now()}
+		plain := !stdoutIsTerminal()
+		if !plain {
+			opts.width = terminalWidth()
+			opts.wrap, _ = cmd.Flags().GetBool("wrap")
+		}
+
+		if groupBy == "" {
+			return printTasks(tasks, columns, opts, plain)
+		}
+
+		groups := groupTasks(tasks, func(t task.Task) []string { return by.keys(t, opts.now) })
+		if by.sort == "" {
+			sortGroups(groups)
+		}
+		titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+		for i, g := range groups {
+			if i > 0 {
+				fmt.Println()
+			}
+			title := fmt.Sprintf("%s (%d)", g.title, len(g.tasks))
+			if plain {
+				fmt.Printf("## %s\n", title)
+			} else {
+				fmt.Println(titleStyle.Render(title))
+			}
+			if err := printTasks(g.tasks, columns, opts, plain); err != nil {
+				return err
+			}
+		}
 		return nil
 	},
 }
 
-func setupTable(tasks []task.Task) *table.Table {
-	columns := []string{"ID", "Name", "Project", "Status", "Created At"}
-	var rows [][]string
+// init registers flags specific to the list command.
+func init() {
+	addFilterFlags(listCmd)
+	listCmd.Flags().String("sort", "", fmt.Sprintf("Sort by fields, e.g. status,created:desc (fields: %s)", strings.Join(db.SortFields(), ", ")))
+	listCmd.Flags().String("columns", "", fmt.Sprintf("Comma-separated columns to show (default: %s)", strings.Join(listColumnKeys(), ",")))
+	listCmd.Flags().Bool("wrap", false, "Wrap long names and projects instead of truncating them")
+	listCmd.Flags().String("group-by", "", fmt.Sprintf("Show a separate table per group (%s)", strings.Join(groupByFields, ", ")))
+	listCmd.Flags().String("date-format", "", "Date display: relative, iso, local, date or a Go time layout (default from config)")
+}
+
+// listColumnKeys returns the names of all available list columns.
+func listColumnKeys() []string {
+	keys := make([]string, len(listColumns))
+	for i, c := range listColumns {
+		keys[i] = c.key
+	}
+	return keys
+}
+
+// parseListColumns resolves a comma-separated column selection. An empty
+// spec selects every column.
+func parseListColumns(spec string) ([]listColumn, error) {
+	if strings.TrimSpace(spec) == "" {
+		return listColumns, nil
+	}
+	var selected []listColumn
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, c := range listColumns {
+			if c.key == name {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, db.Errorf(db.ErrInvalidInput, "unknown column %q (valid: %s)", name, strings.Join(listColumnKeys(), ", "))
+		}
+	}
+	if len(selected) == 0 {
+		return nil, db.Errorf(db.ErrInvalidInput, "no columns selected")
+	}
+	return selected, nil
+}
+
+// groupByFields lists the values accepted by --group-by.
+var groupByFields = []string{"project", "status", "due", "tag"}
+
+// grouping describes how --group-by splits tasks for one field.
+type grouping struct {
+	sort string                                    // Sort field keeping groups in order; empty to order groups by title
+	keys func(t task.Task, now time.Time) []string // Titles of the groups t belongs to
+}
+
+// Titles of the groups for tasks lacking the grouped field.
+const (
+	noProject = "(no project)"
+	noDue     = "No due date"
+	noTag     = "(no tag)"
+)
+
+// groupings maps each --group-by field to how it groups tasks.
+var groupings = map[string]grouping{
+	"project": {"project", func(t task.Task, _ time.Time) []string {
+		if t.Project == "" {
+			return []string{noProject}
+		}
+		return []string{t.Project}
+	}},
+	"status": {"status", func(t task.Task, _ time.Time) []string { return []string{t.Status} }},
+	"due":    {"due", func(t task.Task, now time.Time) []string { return []string{dueBucket(t.Due, now)} }},
+	"tag": {"", func(t task.Task, _ time.Time) []string {
+		if len(t.Tags) == 0 {
+			return []string{noTag}
+		}
+		return t.Tags
+	}},
+}
+
+// dueBucket names the due date group of a task due on due, relative to the
+// local day of now. Sorting by due date keeps the buckets in this order.
+func dueBucket(due, now time.Time) string {
+	today := task.Day(now)
+	switch {
+	case due.IsZero():
+		return noDue
+	case due.Before(today):
+		return "Overdue"
+	case due.Equal(today):
+		return "Today"
+	case due.Before(today.AddDate(0, 0, 8)):
+		return "Next 7 days"
+	}
+	return "Later"
+}
+
+// taskGroup is a titled run of tasks sharing a group key.
+type taskGroup struct {
+	title string
+	tasks []task.Task
+}
 
+// groupTasks splits tasks into groups sharing a key, in the order the
+// groups first appear; a task with several keys joins each of their groups.
+// Keys differing only in case share a group, titled by the first spelling
+// seen, matching the case-insensitive sort of projects.
+func groupTasks(tasks []task.Task, keys func(t task.Task) []string) []taskGroup {
+	var groups []taskGroup
+	index := map[string]int{}
 	for _, t := range tasks {
-		// Create raw strings first
-		idStr := fmt.Sprintf("%d", t.ID)
-		nameStr := t.Name
-		projectStr := t.Project
-		statusStr := t.Status
-		dateStr := t.Created.Format("2006-01-02")
-
-		// Add the row with raw strings
-		row := []string{idStr, nameStr, projectStr, statusStr, dateStr}
+		for _, k := range keys(t) {
+			i, ok := index[strings.ToLower(k)]
+			if !ok {
+				i = len(groups)
+				index[strings.ToLower(k)] = i
+				groups = append(groups, taskGroup{title: k})
+			}
+			groups[i].tasks = append(groups[i].tasks, t)
+		}
+	}
+	return groups
+}
+
+// sortGroups orders groups by title, putting the group of tasks without a
+// tag last.
+func sortGroups(groups []taskGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].title == noTag) != (groups[j].title == noTag) {
+			return groups[j].title == noTag
+		}
+		return groups[i].title < groups[j].title
+	})
+}
+
+// printTasks writes tasks to stdout as a bordered table, or as plain aligned
+// text when plain is set.
+func printTasks(tasks []task.Task, columns []listColumn, opts renderOptions, plain bool) error {
+	if plain {
+		headers, rows := tableCells(tasks, columns, opts)
+		return renderPlain(os.Stdout, headers, rows)
+	}
+	fmt.Println(setupTable(tasks, columns, opts).String())
+	return nil
+}
+
+// tableCells renders the selected columns of each task as raw strings.
+func tableCells(tasks []task.Task, columns []listColumn, opts renderOptions) ([]string, [][]string) {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+	}
+
+	var rows [][]string
+	for _, t := range tasks {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.value(t, opts)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+// setupTable builds a bordered table no wider than opts.width, truncating or
+// wrapping cells and hiding low-priority columns as needed.
+func setupTable(tasks []task.Task, columns []listColumn, opts renderOptions) *table.Table {
+	headers, rawRows := tableCells(tasks, columns, opts)
+
+	fits := make([]fitColumn, len(columns))
+	for i, c := range columns {
+		fits[i] = c.fit
+	}
+	kept, widths := fitColumns(fits, headers, rawRows, opts.width)
+
+	keptColumns := make([]listColumn, len(kept))
+	keptHeaders := make([]string, len(kept))
+	for j, i := range kept {
+		keptColumns[j] = columns[i]
+		keptHeaders[j] = truncate(headers[i], widths[j])
+	}
+
+	var rows [][]string
+	for _, raw := range rawRows {
+		row := make([]string, len(kept))
+		for j, i := range kept {
+			if opts.wrap {
+				row[j] = wrapText(raw[i], widths[j])
+			} else {
+				row[j] = truncate(raw[i], widths[j])
+			}
+		}
 		rows = append(rows, row)
 	}
 
-	// Create the table with raw strings first
 	t := table.New().
-		Headers(columns...).
+		Headers(keptHeaders...).
 		Rows(rows...).
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("238"))).
@@ -64,7 +371,7 @@ func setupTable(tasks []task.Task) *table.Table {
 				return baseStyle.Bold(true).Foreground(lipgloss.Color("212"))
 			}
 
-			if col == 3 && row > 0 {
+			if keptColumns[col].key == "status" && row > 0 {
 				cellValue := rows[row-1][col]
 				switch cellValue {
 				case "todo":
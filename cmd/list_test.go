@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+func TestGroupTasksFoldsCase(t *testing.T) {
+	tasks := []task.Task{
+		{ID: 1, Project: "Work"},
+		{ID: 2, Project: "work"},
+		{ID: 3, Project: "Work"},
+		{ID: 4, Project: ""},
+	}
+	groups := groupTasks(tasks, func(t task.Task) []string { return groupings["project"].keys(t, time.Time{}) })
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(groups), groups)
+	}
+	if groups[0].title != "Work" || len(groups[0].tasks) != 3 {
+		t.Errorf("first group = %s with %d tasks, want Work with 3", groups[0].title, len(groups[0].tasks))
+	}
+	if groups[1].title != "(no project)" || len(groups[1].tasks) != 1 {
+		t.Errorf("second group = %s with %d tasks, want (no project) with 1", groups[1].title, len(groups[1].tasks))
+	}
+}
+
+func TestDueBucket(t *testing.T) {
+	now := time.Date(2026, 10, 19, 23, 30, 0, 0, time.Local)
+	day := func(offset int) time.Time { return task.Day(now).AddDate(0, 0, offset) }
+	tests := []struct {
+		due  time.Time
+		want string
+	}{
+		{time.Time{}, noDue},
+		{day(-1), "Overdue"},
+		{day(0), "Today"},
+		{day(1), "Next 7 days"},
+		{day(7), "Next 7 days"},
+		{day(8), "Later"},
+	}
+	for _, tt := range tests {
+		if got := dueBucket(tt.due, now); got != tt.want {
+			t.Errorf("dueBucket(%s) = %q, want %q", task.FormatDue(tt.due), got, tt.want)
+		}
+	}
+}
+
+func TestGroupTasksByTag(t *testing.T) {
+	tasks := []task.Task{
+		{ID: 1, Tags: []string{"urgent", "home"}},
+		{ID: 2},
+		{ID: 3, Tags: []string{"home"}},
+	}
+	groups := groupTasks(tasks, func(t task.Task) []string { return groupings["tag"].keys(t, time.Time{}) })
+	sortGroups(groups)
+
+	var got []string
+	for _, g := range groups {
+		ids := ""
+		for _, t := range g.tasks {
+			ids += fmt.Sprint(t.ID)
+		}
+		got = append(got, g.title+":"+ids)
+	}
+	if want := "home:13 urgent:1 (no tag):2"; strings.Join(got, " ") != want {
+		t.Errorf("groups = %s, want %s", strings.Join(got, " "), want)
+	}
+}
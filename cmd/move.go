@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move ID",
+	Short: "Change a task's position in the manual ordering",
+	Long: `Reorders a task relative to the others. Tasks are listed by status and
+then by this manual rank, so use it to mark what should be done next.
+Exactly one of --before, --after, --top or --bottom is required.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		idStr := args[0]
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return db.Errorf(db.ErrInvalidInput, "invalid ID %q: %w", idStr, err)
+		}
+
+		before, _ := cmd.Flags().GetInt("before")
+		after, _ := cmd.Flags().GetInt("after")
+		top, _ := cmd.Flags().GetBool("top")
+		bottom, _ := cmd.Flags().GetBool("bottom")
+
+		switch {
+		case cmd.Flags().Changed("before"):
+			err = store.MoveBeforeContext(cmd.Context(), uint(id), uint(before))
+		case cmd.Flags().Changed("after"):
+			err = store.MoveAfterContext(cmd.Context(), uint(id), uint(after))
+		case top:
+			err = store.MoveToTopContext(cmd.Context(), uint(id))
+		case bottom:
+			err = store.MoveToBottomContext(cmd.Context(), uint(id))
+		default:
+			return db.Errorf(db.ErrInvalidInput, "specify where to move the task with --before, --after, --top or --bottom")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to move task %d: %w", id, err)
+		}
+
+		movedTask, err := store.GetTaskContext(cmd.Context(), uint(id))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Task ('%s') moved.\n", movedTask.Name)
+		return nil
+	},
+}
+
+// init registers flags specific to the move command.
+func init() {
+	moveCmd.Flags().Int("before", 0, "Place the task immediately before the task with this ID")
+	moveCmd.Flags().Int("after", 0, "Place the task immediately after the task with this ID")
+	moveCmd.Flags().Bool("top", false, "Place the task before all others")
+	moveCmd.Flags().Bool("bottom", false, "Place the task after all others")
+	moveCmd.MarkFlagsMutuallyExclusive("before", "after", "top", "bottom")
+}
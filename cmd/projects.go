@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+// progressBarWidth is the number of cells used to draw a completion bar.
+const progressBarWidth = 20
+
+var projectsCmd = &cobra.Command{
+	Use:     "projects",
+	Aliases: []string{"summary"},
+	Short:   "Show a per-project summary of your workload",
+	Long: `Aggregates tasks per project, showing counts by status, completion
+percentage, the age of the oldest open task and the most recent activity.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		summaries, err := store.GetProjectSummariesContext(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to summarize projects: %w", err)
+		}
+
+		if len(summaries) == 0 {
+			fmt.Println("No tasks found. Add one with 'taskly add \"My new task\"'")
+			return nil
+		}
+
+		fmt.Println(setupSummaryTable(summaries, time.Now()).String())
+
+		var total, done int
+		for _, s := range summaries {
+			total += s.Total()
+			done += s.Done
+		}
+		fmt.Printf("%d project(s), %d task(s), %d done.\n", len(summaries), total, done)
+		return nil
+	},
+}
+
+func setupSummaryTable(summaries []db.ProjectSummary, now time.Time) *table.Table {
+	columns := []string{"Project", "Todo", "In Progress", "Done", "Progress", "Oldest Open", "Last Activity"}
+	var rows [][]string
+
+	for _, s := range summaries {
+		project := s.Project
+		if project == "" {
+			project = "(none)"
+		}
+		oldest := "-"
+		if !s.OldestOpen.IsZero() {
+			oldest = formatAge(now.Sub(s.OldestOpen))
+		}
+		activity := "-"
+		if !s.LastActivity.IsZero() {
+			activity = formatAge(now.Sub(s.LastActivity)) + " ago"
+		}
+
+		rows = append(rows, []string{
+			project,
+			fmt.Sprintf("%d", s.Todo),
+			fmt.Sprintf("%d", s.InProgress),
+			fmt.Sprintf("%d", s.Done),
+			progressBar(s.Completion(), progressBarWidth),
+			oldest,
+			activity,
+		})
+	}
+
+	return table.New().
+		Headers(columns...).
+		Rows(rows...).
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("238"))).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			baseStyle := lipgloss.NewStyle().Padding(0, 1)
+			if row == 0 {
+				return baseStyle.Bold(true).Foreground(lipgloss.Color("212"))
+			}
+			return baseStyle
+		})
+}
+
+// progressBar renders a fraction between 0 and 1 as a bar followed by a percentage.
+func progressBar(fraction float64, width int) string {
+	if fraction < 0 {
+		fraction = 0
+	} else if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction*float64(width) + 0.5)
+
+	filledStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+
+	return filledStyle.Render(strings.Repeat("█", filled)) +
+		emptyStyle.Render(strings.Repeat("░", width-filled)) +
+		fmt.Sprintf(" %3.0f%%", fraction*100)
+}
+
+// formatAge renders a duration in its largest whole unit, e.g. "45m", "3h", "12d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	default:
+		return fmt.Sprintf("%dw", int(d.Hours()/(24*7)))
+	}
+}
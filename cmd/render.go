@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+	"golang.org/x/term"
+)
+
+// defaultTerminalWidth is used when the terminal size cannot be determined.
+const defaultTerminalWidth = 80
+
+// cellOverhead is the width a bordered table adds around each cell: one
+// space of padding on each side plus the separating border.
+const cellOverhead = 3
+
+// stdoutIsTerminal reports whether standard output is an interactive terminal.
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// terminalWidth returns the width of the terminal attached to stdout, falling
+// back to $COLUMNS and then defaultTerminalWidth.
+func terminalWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return defaultTerminalWidth
+}
+
+// truncate shortens s to at most width display cells, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	return runewidth.Truncate(s, width, "…")
+}
+
+// wrapText breaks s into lines of at most width display cells, preferring
+// word boundaries and hard-wrapping words that are longer than a line.
+func wrapText(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return wrap.String(wordwrap.String(s, width), width)
+}
+
+// fitColumn carries the layout hints fitColumns needs for one column.
+type fitColumn struct {
+	minWidth int // Smallest width the column may shrink to; 0 means fixed
+	priority int // Columns with lower priority are dropped first; <0 is never dropped
+}
+
+// fitColumns chooses which columns to show and how wide each may be so a
+// bordered table of the given cells fits within maxWidth. Flexible columns are
+// shrunk towards their minimum first; if that is not enough, droppable columns
+// are removed in priority order. It returns the indices of the kept columns
+// and their content widths.
+func fitColumns(cols []fitColumn, headers []string, rows [][]string, maxWidth int) ([]int, []int) {
+	natural := make([]int, len(cols))
+	for i := range cols {
+		natural[i] = runewidth.StringWidth(headers[i])
+		for _, row := range rows {
+			for _, line := range strings.Split(row[i], "\n") {
+				if w := runewidth.StringWidth(line); w > natural[i] {
+					natural[i] = w
+				}
+			}
+		}
+	}
+
+	kept := make([]int, len(cols))
+	for i := range cols {
+		kept[i] = i
+	}
+
+	for {
+		widths := make([]int, len(kept))
+		total := 1 // left border
+		for j, i := range kept {
+			widths[j] = natural[i]
+			total += widths[j] + cellOverhead
+		}
+
+		// Shrink the widest flexible column one cell at a time.
+		for total > maxWidth {
+			widest := -1
+			for j, i := range kept {
+				if cols[i].minWidth == 0 || widths[j] <= cols[i].minWidth {
+					continue
+				}
+				if widest < 0 || widths[j] > widths[widest] {
+					widest = j
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			widths[widest]--
+			total--
+		}
+		if total <= maxWidth {
+			return kept, widths
+		}
+
+		// Still too wide: drop the lowest-priority droppable column.
+		drop := -1
+		for j, i := range kept {
+			if cols[i].priority < 0 {
+				continue
+			}
+			if drop < 0 || cols[i].priority < cols[kept[drop]].priority {
+				drop = j
+			}
+		}
+		if drop < 0 {
+			return kept, widths
+		}
+		kept = append(kept[:drop:drop], kept[drop+1:]...)
+	}
+}
+
+// plainSpaces replaces the characters that would split a cell or a row of
+// plain output.
+var plainSpaces = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// renderPlain writes headers and rows as tab-aligned text without borders or
+// colours, suitable for pipes and files. Tabs and line breaks in cells become
+// spaces, so each row stays on one line with its columns in place.
+func renderPlain(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, plainLine(headers))
+	for _, row := range rows {
+		fmt.Fprintln(tw, plainLine(row))
+	}
+	return tw.Flush()
+}
+
+// plainLine joins cells with tabs for renderPlain.
+func plainLine(cells []string) string {
+	clean := make([]string, len(cells))
+	for i, c := range cells {
+		clean[i] = plainSpaces.Replace(c)
+	}
+	return strings.Join(clean, "\t")
+}
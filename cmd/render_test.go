@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderPlainKeepsRowsIntact(t *testing.T) {
+	var buf bytes.Buffer
+	rows := [][]string{
+		{"1", "Call\tBob\r\nabout lunch", "home"},
+		{"2", "Plain", "work"},
+	}
+	if err := renderPlain(&buf, []string{"ID", "NAME", "PROJECT"}, rows); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 rows:\n%s", len(lines), buf.String())
+	}
+	for i, want := range [][]string{{"1", "Call", "Bob", "about", "lunch", "home"}, {"2", "Plain", "work"}} {
+		if got := strings.Fields(lines[i+1]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("row %d = %q, want the fields %q", i+1, lines[i+1], want)
+		}
+	}
+	// The project column starts at the same offset in every line.
+	col := strings.Index(lines[0], "PROJECT")
+	if strings.Index(lines[1], "home") != col || strings.Index(lines[2], "work") != col {
+		t.Errorf("columns are misaligned:\n%s", buf.String())
+	}
+}
@@ -1,16 +1,27 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/ashish0kumar/taskly/internal/config"
 	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/filestore"
+	"github.com/ashish0kumar/taskly/internal/hooks"
+	"github.com/ashish0kumar/taskly/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
 
-// dbConn holds the database connection for use by commands within this package
-var dbConn *db.TaskDB
+// appConfig holds user preferences loaded from the config file
+var appConfig = config.Default()
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -19,8 +30,20 @@ var rootCmd = &cobra.Command{
 	Long: `Taskly helps you manage your tasks efficiently from the command line.
 You can add, list, update, delete, and view tasks on a Kanban board.`,
 	Args: cobra.NoArgs,
-	// PersistentPreRunE runs before any command's RunE. Sets up DB connection
+	// Errors are printed once by main, without the usage text.
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	// PersistentPreRunE runs before any command's RunE. Loads config and sets up DB connection
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Cobra checks these after this hook; check them first so they are
+		// reported as usage errors before any database is opened.
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return usageError(cmd, err)
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return usageError(cmd, err)
+		}
+
 		// Skip database setup for built-in commands.
 		// Also skip 'where' command as it doesn't need a live DB connection
 		if cmd.Name() == "help" || cmd.Name() == "version" || cmd.Name() == "where" ||
@@ -29,18 +52,46 @@ You can add, list, update, delete, and view tasks on a Kanban board.`,
 		}
 
 		var err error
-		// Open DB connection and store it in the package variable.
-		dbConn, err = db.OpenDB()
+		// Load user preferences; a missing config file yields defaults.
+		appConfig, err = config.Load()
 		if err != nil {
-			return fmt.Errorf("failed to initialize database: %w", err)
+			return err
+		}
+		run := runFrom(cmd)
+		if run.timeout, err = selectedTimeout(cmd); err != nil {
+			return err
 		}
+		if run.timeout > 0 && !isLongRunning(cmd) {
+			ctx, cancel := context.WithTimeout(cmd.Context(), run.timeout)
+			cmd.SetContext(ctx)
+			run.cancelTimeout = cancel
+		}
+
+		// A store injected with WithStore is used as is; otherwise open the
+		// configured backend and hand it to the command through its context.
+		if _, ok := cmd.Context().Value(storeKey{}).(*storeHandle); ok {
+			return nil
+		}
+		store, err := openStore(cmd)
+		if err != nil {
+			return err
+		}
+		setupWebhooks(cmd, store)
+		hooksDir, err := config.GetHooksDir()
+		if err != nil {
+			store.Close()
+			return err
+		}
+		store = hooks.Wrap(store, hooksDir, os.Stderr)
+		cmd.SetContext(context.WithValue(cmd.Context(), storeKey{}, &storeHandle{store: store, owned: true}))
 		return nil
 	},
-	// PersistentPostRunE runs after command's RunE. Closes DB connection.
+	// PersistentPostRunE runs after command's RunE. Closes the store it opened.
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
-		// Only close if dbConn was initialized (i.e., not skipped in PreRun)
-		if dbConn != nil {
-			if err := dbConn.Close(); err != nil {
+		// Injected stores belong to the caller, who closes them.
+		if h, ok := cmd.Context().Value(storeKey{}).(*storeHandle); ok && h.owned {
+			finishWebhooks(cmd)
+			if err := h.store.Close(); err != nil {
 				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
 			}
 		}
@@ -54,8 +105,105 @@ You can add, list, update, delete, and view tasks on a Kanban board.`,
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The first SIGINT or SIGTERM cancels the running command; a second one
+// terminates the process as usual.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ExecuteContext(ctx)
+}
+
+// ExecuteContext runs the root command with ctx, which may carry a store set
+// with WithStore. Use ExitCode to turn the returned error into an exit code.
+func ExecuteContext(ctx context.Context) error {
+	run := &runState{}
+	cmd, err := rootCmd.ExecuteContextC(context.WithValue(ctx, runKey{}, run))
+	if run.cancelTimeout != nil {
+		run.cancelTimeout()
+	}
+	// Cobra only sets a command's context when it has none, so clear it to
+	// keep a later call from reusing this run's store and deadline.
+	cmd.SetContext(nil)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w (gave up after %s; see --timeout)", err, run.timeout)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("interrupted: %w", err)
+	case errors.Is(err, db.ErrInvalidInput):
+		return fmt.Errorf("%w\nRun '%s --help' for usage.", err, cmd.CommandPath())
+	case errors.Is(err, db.ErrBusy):
+		return fmt.Errorf("%w\nAnother taskly process is writing to the database; try again shortly.", err)
+	}
+	return err
+}
+
+// runKey is the context key under which a command finds its runState.
+type runKey struct{}
+
+// runState holds what PersistentPreRunE sets up for one run of a command
+// and ExecuteContext releases afterwards. Keeping it in the context rather
+// than in package variables keeps runs from seeing each other's settings.
+type runState struct {
+	// timeout bounds the command's store operations; zero means no limit.
+	// Long-running commands apply it to each operation rather than the
+	// session.
+	timeout time.Duration
+
+	// cancelTimeout releases the deadline set on the command's context.
+	cancelTimeout context.CancelFunc
+
+	// dispatcher sends the command's webhooks; nil if none are set up.
+	dispatcher *webhook.Dispatcher
+
+	// stopDispatcher stops the background sending of long-running commands
+	// and waits for it to finish.
+	stopDispatcher func()
+
+	// queued records that the command queued at least one webhook delivery.
+	queued atomic.Bool
+}
+
+// runFrom returns the run state of cmd, or an empty one if cmd was not
+// started through ExecuteContext.
+func runFrom(cmd *cobra.Command) *runState {
+	if ctx := cmd.Context(); ctx != nil {
+		if run, ok := ctx.Value(runKey{}).(*runState); ok {
+			return run
+		}
+	}
+	return &runState{}
+}
+
+// storeKey is the context key under which commands find their store.
+type storeKey struct{}
+
+// storeHandle is the context value for storeKey.
+type storeHandle struct {
+	store db.Store
+	owned bool // Opened by taskly itself, so closed after the command
+}
+
+// WithStore returns a context that makes commands use store instead of
+// opening the configured backend. The caller remains responsible for
+// closing it.
+func WithStore(ctx context.Context, store db.Store) context.Context {
+	return context.WithValue(ctx, storeKey{}, &storeHandle{store: store})
+}
+
+// storeFrom returns the store set up for cmd by PersistentPreRunE.
+func storeFrom(cmd *cobra.Command) (db.Store, error) {
+	if ctx := cmd.Context(); ctx != nil {
+		if h, ok := ctx.Value(storeKey{}).(*storeHandle); ok && h.store != nil {
+			return h.store, nil
+		}
+	}
+	return nil, fmt.Errorf("database connection not initialized")
 }
 
 // init registers child commands and flags.
@@ -66,4 +214,102 @@ func init() {
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(whereCmd)
 	rootCmd.AddCommand(kanbanCmd)
+	rootCmd.AddCommand(projectsCmd)
+	rootCmd.AddCommand(tuiCmd)
+	rootCmd.AddCommand(moveCmd)
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreBackupCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(webhooksCmd)
+
+	rootCmd.SetFlagErrorFunc(usageError)
+	for _, c := range append(rootCmd.Commands(), rootCmd) {
+		if c.Args != nil {
+			c.Args = checkedArgs(c.Args)
+		}
+	}
+
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Give up on the task store after this long, e.g. 10s (0 disables); defaults to the \"timeout\" config setting")
+	rootCmd.PersistentFlags().String("backend", "", fmt.Sprintf("Storage backend (%s); defaults to the \"backend\" config setting", strings.Join(config.Backends(), ", ")))
+}
+
+// selectedBackend returns the backend named by --backend or, failing that,
+// the config file.
+func selectedBackend(cmd *cobra.Command) (string, error) {
+	if !cmd.Flags().Changed("backend") {
+		return appConfig.Backend, nil
+	}
+	backend, _ := cmd.Flags().GetString("backend")
+	if err := config.ValidateBackend(backend); err != nil {
+		return "", err
+	}
+	return backend, nil
+}
+
+// selectedTimeout returns the timeout given by --timeout or, failing that,
+// the config file.
+func selectedTimeout(cmd *cobra.Command) (time.Duration, error) {
+	if !cmd.Flags().Changed("timeout") {
+		return appConfig.OperationTimeout()
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout < 0 {
+		return 0, db.Errorf(db.ErrInvalidInput, "invalid timeout %s: must not be negative", timeout)
+	}
+	return timeout, nil
+}
+
+// isLongRunning reports whether cmd runs until the user stops it, like the
+// full-screen interfaces and the server. These apply the timeout to each
+// store operation instead of the whole command.
+func isLongRunning(cmd *cobra.Command) bool {
+	return cmd.Name() == "tui" || cmd.Name() == "kanban" || cmd.Name() == "serve"
+}
+
+// openStore opens the task store for the selected backend.
+func openStore(cmd *cobra.Command) (db.Store, error) {
+	backend, err := selectedBackend(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if backend == config.BackendFiles {
+		dir, err := filestore.FindDir(".")
+		if err != nil {
+			return nil, fmt.Errorf("could not locate task directory: %w", err)
+		}
+		return filestore.Open(dir)
+	}
+
+	tdb, err := db.OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return tdb, nil
+}
+
+// baseStore returns the backend beneath the hook runner wrapping store, if
+// any.
+func baseStore(store db.Store) db.Store {
+	if h, ok := store.(*hooks.Store); ok {
+		return h.Unwrap()
+	}
+	return store
+}
+
+// sqliteDB returns the SQLite database for commands that work on the
+// database file itself, failing for other backends.
+func sqliteDB(cmd *cobra.Command) (*db.TaskDB, error) {
+	store, err := storeFrom(cmd)
+	if err != nil {
+		return nil, err
+	}
+	tdb, ok := baseStore(store).(*db.TaskDB)
+	if !ok {
+		return nil, db.Errorf(db.ErrInvalidInput, "'taskly %s' is only available with the %s backend", cmd.Name(), config.BackendSQLite)
+	}
+	return tdb, nil
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ashish0kumar/taskly/internal/memstore"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// run executes taskly with args against store.
+func run(t *testing.T, store *memstore.Store, args ...string) error {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	rootCmd.SetArgs(args)
+	return ExecuteContext(WithStore(context.Background(), store))
+}
+
+func TestCommandsUseInjectedStore(t *testing.T) {
+	store := memstore.New()
+	if err := run(t, store, "add", "Injected", "--project", "tests"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := run(t, store, "start", "1"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	got, err := store.GetTaskContext(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Name != "Injected" || got.Project != "tests" || got.Status != task.InProgress.String() {
+		t.Errorf("stored task = %+v", got)
+	}
+
+	// The store belongs to the caller, so commands must not close it.
+	if err := run(t, store, "delete", "1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if tasks, _ := store.GetTasksContext(context.Background()); len(tasks) != 0 {
+		t.Errorf("tasks left after delete: %+v", tasks)
+	}
+}
+
+func TestSQLiteOnlyCommandsRefuseOtherStores(t *testing.T) {
+	if err := run(t, memstore.New(), "doctor"); err == nil {
+		t.Error("doctor succeeded without a SQLite database")
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"list"}, ExitOK},
+		{[]string{"delete", "99"}, ExitNotFound},
+		{[]string{"delete", "abc"}, ExitInvalidInput},
+		{[]string{"add"}, ExitInvalidInput},
+		{[]string{"list", "--no-such-flag"}, ExitInvalidInput},
+		{[]string{"no-such-command"}, ExitInvalidInput},
+		{[]string{"doctor"}, ExitInvalidInput},
+	}
+	for _, tc := range tests {
+		err := run(t, memstore.New(), tc.args...)
+		if got := ExitCode(err); got != tc.want {
+			t.Errorf("taskly %v: exit code %d (err %v), want %d", tc.args, got, err, tc.want)
+		}
+	}
+}
+
+func TestTimeoutIsPerRun(t *testing.T) {
+	t.Cleanup(func() {
+		f := rootCmd.PersistentFlags().Lookup("timeout")
+		f.Value.Set("0s")
+		f.Changed = false
+	})
+	store := memstore.New()
+	err := run(t, store, "list", "--timeout", "1ns")
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "gave up after 1ns") {
+		t.Fatalf("list --timeout 1ns = %v, want a deadline error naming the timeout", err)
+	}
+	// The deadline belonged to that run only.
+	if err := run(t, store, "list", "--timeout", "0"); err != nil {
+		t.Fatalf("list after a timed-out run: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/server"
+)
+
+// shutdownGrace is how long serve waits for requests in flight to finish
+// after being told to stop.
+const shutdownGrace = 5 * time.Second
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve tasks over a local HTTP JSON API and web view",
+	Long: `Starts an HTTP server exposing the tasks as JSON, so dashboards and scripts
+in any language can read and change them, along with a web view at / that
+shows them as a list or a kanban board in the browser:
+
+  GET    /api/tasks          list tasks (?project=, ?status=, ?hide_done=,
+                             ?done_since=<RFC 3339>, ?sort=)
+  POST   /api/tasks          create a task
+  GET    /api/tasks/{id}     get a task
+  PATCH  /api/tasks/{id}     change a task's name, project or status
+  DELETE /api/tasks/{id}     delete a task
+  GET    /api/events         a server-sent event stream of task changes
+  GET    /api/openapi.json   the OpenAPI description of the above
+
+The event stream reports every task created, updated or deleted, whether
+through the API or the taskly command, with the full task and a sequence
+number, which with the server's start time makes up the event ID.
+Reconnecting with Last-Event-ID replays the events missed in between, or
+sends a reset event after the server restarted.
+
+Requests with a body must send it as application/json. Responses carry an
+ETag. Send it back in If-Match when changing or deleting
+a task to have the request refused with 412 if someone else changed the
+task in the meantime, or in If-None-Match to get 304 when nothing changed.
+
+With --token (or the TASKLY_API_TOKEN environment variable), requests must
+send "Authorization: Bearer <token>". Without a token, only requests for
+localhost or a loopback address are answered, so set one when listening on
+an address other than localhost.
+
+The server stops on Ctrl+C. WIP limits from the config file apply, and
+--timeout bounds each request.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		addr, _ := cmd.Flags().GetString("addr")
+		token, _ := cmd.Flags().GetString("token")
+		if !cmd.Flags().Changed("token") {
+			token = os.Getenv("TASKLY_API_TOKEN")
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		if token == "" && !isLoopback(listener.Addr()) {
+			fmt.Fprintf(os.Stderr, "Warning: serving on %s without a token; only requests for localhost will be answered.\n", listener.Addr())
+		}
+
+		api := server.New(store, server.Options{Token: token, Timeout: runFrom(cmd).timeout, WIPLimits: appConfig.WIPLimits})
+		srv := &http.Server{Handler: api.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+		// Stopping the watcher ends the event streams, which would
+		// otherwise keep Shutdown waiting.
+		watchCtx, stopWatch := context.WithCancel(cmd.Context())
+		defer stopWatch()
+		watchErr := make(chan error, 1)
+		go func() { watchErr <- api.Watch(watchCtx) }()
+		errc := make(chan error, 1)
+		go func() { errc <- srv.Serve(listener) }()
+		fmt.Printf("Serving taskly at http://%s/ (press Ctrl+C to stop)\n", listener.Addr())
+
+		var failure error
+		select {
+		case err := <-errc:
+			return fmt.Errorf("server failed: %w", err)
+		case err := <-watchErr:
+			failure = fmt.Errorf("failed to watch for changes: %w", err)
+		case <-cmd.Context().Done():
+		}
+		stopWatch()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to stop server: %w", err)
+		}
+		if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		if failure != nil {
+			return failure
+		}
+		fmt.Println("Server stopped.")
+		return nil
+	},
+}
+
+// init registers flags specific to the serve command.
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().String("token", "", "Bearer token clients must send (default $TASKLY_API_TOKEN)")
+}
+
+// isLoopback reports whether addr only accepts connections from this machine.
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/hooks"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start ID",
+	Short: "Mark a task as in progress",
+	Long: `Moves a task to "in progress". This is a shortcut for 'taskly update ID -s 1'
+and is refused if it would exceed the WIP limit, unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		idStr := args[0]
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return db.Errorf(db.ErrInvalidInput, "invalid ID %q: %w", idStr, err)
+		}
+
+		current, err := store.GetTaskContext(cmd.Context(), uint(id))
+		if err != nil {
+			return fmt.Errorf("failed to start task %d: %w", id, err)
+		}
+		status := task.InProgress.String()
+		if current.Status == status {
+			fmt.Printf("Task ('%s') is already in progress.\n", current.Name)
+			return nil
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		startedTask, err := hooks.Update(cmd.Context(), store, uint(id), db.Changes{Status: &status}, wipGuard(store, force))
+		if err != nil {
+			return fmt.Errorf("failed to start task %d: %w", id, err)
+		}
+		fmt.Printf("Task ('%s') started.\n", startedTask.Name)
+		return nil
+	},
+}
+
+// init registers flags specific to the start command.
+func init() {
+	startCmd.Flags().BoolP("force", "f", false, "Start the task even if it exceeds the WIP limit")
+}
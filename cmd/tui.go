@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/tui"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Manage tasks in an interactive full-screen interface",
+	Long: `Opens a full-screen task manager with a filterable task list and a detail
+pane. Press / to filter, a to add, e to edit, s/S to change status, d to
+delete (with confirmation), u to undo the last change and q to quit.
+All changes are saved to the database immediately. Adding or moving a task
+into a status at its WIP limit ("wip_limits" in the config file) is refused.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		p := tea.NewProgram(tui.New(store, appConfig.WIPLimits, runFrom(cmd).timeout), tea.WithAltScreen(), tea.WithContext(cmd.Context()))
+		if _, err := p.Run(); err != nil {
+			return fmt.Errorf("tui error: %w", err)
+		}
+		return nil
+	},
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/hooks"
 	"github.com/ashish0kumar/taskly/internal/task"
 
 	"github.com/spf13/cobra"
@@ -12,19 +15,24 @@ import (
 
 var updateCmd = &cobra.Command{
 	Use:   "update ID",
-	Short: "Update a task's details (name, project, status)",
-	Long: `Updates the specified task's name, project, or status.
-Provide the task ID and use flags for the fields you want to change.`,
+	Short: "Update a task's details (name, project, status, tags, due date)",
+	Long: `Updates the specified task's name, project, status, tags or due date.
+Provide the task ID and use flags for the fields you want to change;
+--tag and --untag add and remove tags, keeping the others, and --due none
+removes the due date.
+A status change that would exceed a configured WIP limit, including one
+made by a pre-update hook, is refused unless --force is given.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if dbConn == nil {
-			return fmt.Errorf("database connection not initialized")
+		store, err := storeFrom(cmd)
+		if err != nil {
+			return err
 		}
 
 		idStr := args[0]
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			return fmt.Errorf("invalid ID %q: %w", idStr, err)
+			return db.Errorf(db.ErrInvalidInput, "invalid ID %q: %w", idStr, err)
 		}
 
 		// Use pointers to detect which flags were actually set
@@ -60,13 +68,36 @@ Provide the task ID and use flags for the fields you want to change.`,
 				for _, s := range allStatuses {
 					validOptions = append(validOptions, fmt.Sprintf("%d=%s", s.Int(), s.String()))
 				}
-				return fmt.Errorf("invalid status value: %d. Use %s", sInt, strings.Join(validOptions, ", "))
+				return db.Errorf(db.ErrInvalidInput, "invalid status value: %d. Use %s", sInt, strings.Join(validOptions, ", "))
 			}
 			statusStrPtr = &sStr
 		}
 
-		// Call the exported Update method
-		updatedTask, err := dbConn.Update(uint(id), name, project, statusStrPtr)
+		var tags *[]string
+		if cmd.Flags().Changed("tag") || cmd.Flags().Changed("untag") {
+			current, err := store.GetTaskContext(cmd.Context(), uint(id))
+			if err != nil {
+				return fmt.Errorf("failed to update task %d: %w", id, err)
+			}
+			add, _ := cmd.Flags().GetStringSlice("tag")
+			remove, _ := cmd.Flags().GetStringSlice("untag")
+			t := retag(current.Tags, add, remove)
+			tags = &t
+		}
+
+		var due *time.Time
+		if cmd.Flags().Changed("due") {
+			spec, _ := cmd.Flags().GetString("due")
+			d, err := task.ParseDue(spec, time.Now())
+			if err != nil {
+				return db.Errorf(db.ErrInvalidInput, "%w", err)
+			}
+			due = &d
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		ch := db.Changes{Name: name, Project: project, Status: statusStrPtr, Tags: tags, Due: due}
+		updatedTask, err := hooks.Update(cmd.Context(), store, uint(id), ch, wipGuard(store, force))
 		if err != nil {
 			return fmt.Errorf("failed to update task %d: %w", id, err)
 		}
@@ -79,4 +110,23 @@ func init() {
 	updateCmd.Flags().StringP("name", "n", "", "Update the name of the task")
 	updateCmd.Flags().StringP("project", "p", "", "Update the project of the task")
 	updateCmd.Flags().IntP("status", "s", -1, "Update status: 0=todo, 1=in progress, 2=done")
+	updateCmd.Flags().BoolP("force", "f", false, "Change status even if it exceeds the WIP limit")
+	updateCmd.Flags().StringSliceP("tag", "t", nil, "Add a tag; repeat or separate tags with commas")
+	updateCmd.Flags().StringSlice("untag", nil, "Remove a tag; repeat or separate tags with commas")
+	updateCmd.Flags().StringP("due", "d", "", "Due date: YYYY-MM-DD, today, tomorrow, a number of days or weeks ahead like 3d, or none")
+}
+
+// retag returns tags with add added and remove removed, normalised.
+func retag(tags, add, remove []string) []string {
+	removed := map[string]bool{}
+	for _, tag := range task.NormalizeTags(remove) {
+		removed[tag] = true
+	}
+	var out []string
+	for _, tag := range task.NormalizeTags(append(append([]string{}, tags...), add...)) {
+		if !removed[tag] {
+			out = append(out, tag)
+		}
+	}
+	return out
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/memstore"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+func TestUpdateTags(t *testing.T) {
+	store := memstore.New()
+	if err := run(t, store, "add", "Buy milk", "--tag", "Errands,home"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := run(t, store, "update", "1", "--tag", "shop", "--untag", "home"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := store.GetTaskContext(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if tags := strings.Join(got.Tags, ","); tags != "errands,shop" {
+		t.Errorf("tags = %q, want errands,shop", tags)
+	}
+}
+
+func TestUpdateDue(t *testing.T) {
+	// Flag values outlive a command run, so later tests must not inherit them.
+	t.Cleanup(func() {
+		for _, c := range []*cobra.Command{addCmd, updateCmd} {
+			f := c.Flags().Lookup("due")
+			f.Value.Set("")
+			f.Changed = false
+		}
+	})
+
+	store := memstore.New()
+	if err := run(t, store, "add", "Pay rent", "--due", "2026-11-01"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	got, _ := store.GetTaskContext(context.Background(), 1)
+	if d := task.FormatDue(got.Due); d != "2026-11-01" {
+		t.Errorf("due after add = %q, want 2026-11-01", d)
+	}
+
+	if err := run(t, store, "update", "1", "--due", "soon"); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("update --due soon: %v, want ErrInvalidInput", err)
+	}
+	if err := run(t, store, "update", "1", "--due", "none"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, _ = store.GetTaskContext(context.Background(), 1)
+	if !got.Due.IsZero() {
+		t.Errorf("due after --due none = %v, want none", got.Due)
+	}
+}
+
+func TestRetag(t *testing.T) {
+	tests := []struct {
+		tags, add, remove []string
+		want              string
+	}{
+		{nil, []string{"a"}, nil, "a"},
+		{[]string{"a", "b"}, []string{"B", "c"}, []string{"a"}, "b,c"},
+		{[]string{"a"}, nil, []string{"a", "missing"}, ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(retag(tt.tags, tt.add, tt.remove), ","); got != tt.want {
+			t.Errorf("retag(%q, +%q, -%q) = %q, want %q", tt.tags, tt.add, tt.remove, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ashish0kumar/taskly/internal/config"
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+	"github.com/ashish0kumar/taskly/internal/webhook"
+)
+
+const (
+	// webhookBudget is how long a command spends sending webhooks before
+	// exiting; whatever is left is sent by `taskly webhooks` or serve.
+	webhookBudget = 5 * time.Second
+
+	// webhookInterval is how often long-running commands send webhooks.
+	webhookInterval = 2 * time.Second
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Send pending webhooks and show the delivery queue",
+	Long: `Sends the webhook deliveries that are due and lists the ones still queued:
+those waiting for a retry after failing, and those given up on after failing
+too often. Commands that change tasks send their own deliveries when they
+finish, but leave retries to this command and to serve, tui and kanban,
+which send due deliveries every few seconds while they run.
+
+Webhooks are configured in the "webhooks" section of the config file and
+require the sqlite backend.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tdb, err := sqliteDB(cmd)
+		if err != nil {
+			return err
+		}
+
+		retry, _ := cmd.Flags().GetBool("retry")
+		if retry {
+			n, err := tdb.RetryFailedContext(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Retrying %d failed deliveries.\n", n)
+		}
+		if d := runFrom(cmd).dispatcher; d != nil {
+			// Running out of time leaves the rest queued, as listed below.
+			result, err := deliverWebhooks(cmd.Context(), d)
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("failed to send webhooks: %w", err)
+			}
+			if result != (webhook.Result{}) {
+				fmt.Printf("Sent %d, %d to retry, %d given up on, %d dropped for removed webhooks.\n",
+					result.Sent, result.Failed, result.GivenUp, result.Dropped)
+			}
+		}
+
+		deliveries, err := tdb.DeliveriesContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			fmt.Println("No webhook deliveries are queued.")
+			return nil
+		}
+		return renderPlain(os.Stdout, []string{"ID", "EVENT", "URL", "ATTEMPTS", "NEXT ATTEMPT", "LAST ERROR"}, deliveryRows(deliveries, time.Now()))
+	},
+}
+
+// init registers flags specific to the webhooks command.
+func init() {
+	webhooksCmd.Flags().Bool("retry", false, "Try deliveries that were given up on again")
+}
+
+// deliveryRows formats deliveries for the queue listing.
+func deliveryRows(deliveries []db.Delivery, now time.Time) [][]string {
+	rows := make([][]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		next := "gave up"
+		switch {
+		case d.NextAttempt.IsZero():
+		case !d.NextAttempt.After(now):
+			next = "now"
+		default:
+			next = "in " + formatAge(d.NextAttempt.Sub(now))
+		}
+		lastErr := d.LastError
+		if lastErr == "" {
+			lastErr = "-"
+		}
+		rows = append(rows, []string{strconv.FormatInt(d.ID, 10), string(d.Event), d.URL, strconv.Itoa(d.Attempts), next, truncate(lastErr, 60)})
+	}
+	return rows
+}
+
+// setupWebhooks makes changes to store queue deliveries to the configured
+// webhooks, and sends them in the background for long-running commands.
+// Other commands send the ones they queued when they finish, with
+// finishWebhooks.
+func setupWebhooks(cmd *cobra.Command, store db.Store) {
+	if len(appConfig.Webhooks) == 0 {
+		return
+	}
+	tdb, ok := store.(*db.TaskDB)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Warning: webhooks are only sent with the %s backend.\n", config.BackendSQLite)
+		return
+	}
+	run := runFrom(cmd)
+	route := webhook.Router(appConfig.Webhooks)
+	tdb.SetRouter(func(c task.Change) ([]db.OutboxEntry, error) {
+		entries, err := route(c)
+		if len(entries) > 0 {
+			run.queued.Store(true)
+		}
+		return entries, err
+	})
+	d := webhook.NewDispatcher(tdb, appConfig.Webhooks, webhook.Options{})
+	run.dispatcher = d
+
+	if isLongRunning(cmd) {
+		ctx, cancel := context.WithCancel(cmd.Context())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			d.Run(ctx, webhookInterval)
+		}()
+		run.stopDispatcher = func() {
+			cancel()
+			<-done
+		}
+	}
+}
+
+// finishWebhooks sends the deliveries the command queued before the store
+// is closed. It does nothing for commands that changed no tasks, and leaves
+// retries of earlier failures to `taskly webhooks` and the long-running
+// commands, so a webhook that is down does not slow down every command.
+func finishWebhooks(cmd *cobra.Command) {
+	run := runFrom(cmd)
+	if run.dispatcher == nil {
+		return
+	}
+	if run.stopDispatcher != nil {
+		run.stopDispatcher()
+	}
+	if !run.queued.Load() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), webhookBudget)
+	defer cancel()
+	if _, err := run.dispatcher.DeliverNew(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Warning: could not send webhooks: %v\n", err)
+	}
+}
+
+// deliverWebhooks sends the due deliveries, spending at most webhookBudget
+// so an unreachable webhook cannot hold up the command for long.
+func deliverWebhooks(ctx context.Context, d *webhook.Dispatcher) (webhook.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookBudget)
+	defer cancel()
+	return d.Deliver(ctx)
+}
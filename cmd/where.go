@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/ashish0kumar/taskly/internal/config"
 	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/filestore"
 
 	"github.com/spf13/cobra"
 )
@@ -11,9 +13,59 @@ import (
 var whereCmd = &cobra.Command{
 	Use:   "where",
 	Short: "Show the location of the tasks database file",
-	Long:  `Displays the full path to the SQLite database file where tasks are stored.`,
-	Args:  cobra.NoArgs,
+	Long: `Displays the full path to the SQLite database file where tasks are stored,
+or the task directory when using the files backend.
+Use --config to show the path of the config file, --backups to show the
+directory holding database backups, or --hooks to show the directory holding
+hook scripts, instead.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if showConfig, _ := cmd.Flags().GetBool("config"); showConfig {
+			configPath, err := config.GetConfigPath()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Println(configPath)
+			return err
+		}
+
+		if showBackups, _ := cmd.Flags().GetBool("backups"); showBackups {
+			backupDir, err := db.GetBackupDir()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Println(backupDir)
+			return err
+		}
+
+		if showHooks, _ := cmd.Flags().GetBool("hooks"); showHooks {
+			hooksDir, err := config.GetHooksDir()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Println(hooksDir)
+			return err
+		}
+
+		// 'where' skips the usual setup, so read the backend choice here.
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		appConfig = cfg
+		backend, err := selectedBackend(cmd)
+		if err != nil {
+			return err
+		}
+		if backend == config.BackendFiles {
+			dir, err := filestore.FindDir(".")
+			if err != nil {
+				return fmt.Errorf("could not locate task directory: %w", err)
+			}
+			_, err = fmt.Println(dir)
+			return err
+		}
+
 		// Get the path using the exported function from the db package
 		dbPath, err := db.GetStoragePath()
 		if err != nil {
@@ -24,3 +76,11 @@ var whereCmd = &cobra.Command{
 		return err
 	},
 }
+
+// init registers flags specific to the where command.
+func init() {
+	whereCmd.Flags().Bool("config", false, "Show the config file path instead of the database path")
+	whereCmd.Flags().Bool("backups", false, "Show the backup directory instead of the database path")
+	whereCmd.Flags().Bool("hooks", false, "Show the hook script directory instead of the database path")
+	whereCmd.MarkFlagsMutuallyExclusive("config", "backups", "hooks")
+}
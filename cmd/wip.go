@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/hooks"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// wipGuard applies the WIP limits to the status a change leaves a task in,
+// after any pre-hooks changed it; only a move into a different status counts.
+func wipGuard(store db.Store, force bool) hooks.Guard {
+	return func(ctx context.Context, before, after task.Task) error {
+		if after.Status == before.Status {
+			return nil
+		}
+		return checkWIPLimit(ctx, store, after.Status, force)
+	}
+}
+
+// checkWIPLimit reports whether moving one more task in store into status
+// would exceed its configured work-in-progress limit. With force set, the move is allowed
+// and a warning printed instead of returning an error.
+func checkWIPLimit(ctx context.Context, store db.Store, status string, force bool) error {
+	limit, ok := appConfig.WIPLimit(status)
+	if !ok {
+		return nil
+	}
+	counts, err := store.CountTasksByStatusContext(ctx)
+	if err != nil {
+		return err
+	}
+	if counts[status] < limit {
+		return nil
+	}
+	if !force {
+		return db.Errorf(db.ErrConflict, "'%s' is at its WIP limit (%d/%d); finish something first or use --force", status, counts[status], limit)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: '%s' is now over its WIP limit (%d/%d).\n", status, counts[status]+1, limit)
+	return nil
+}
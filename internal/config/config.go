@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gap "github.com/muesli/go-app-paths"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Storage backends selectable with the "backend" setting or --backend.
+const (
+	BackendSQLite = "sqlite" // A SQLite database in the user's data directory
+	BackendFiles  = "files"  // Task files in the nearest .taskly directory
+)
+
+// Backends returns the valid storage backend names. Exported
+func Backends() []string {
+	return []string{BackendSQLite, BackendFiles}
+}
+
+// Config holds user preferences read from the taskly config file. Exported
+type Config struct {
+	// DateFormat is the default for --date-format: relative, iso, local,
+	// date, or a Go time layout such as "Jan 2 15:04".
+	DateFormat string `json:"date_format,omitempty"`
+
+	// WIPLimits caps how many tasks may be in each status, keyed by status
+	// name (e.g. "in progress"). Statuses without an entry are unlimited.
+	WIPLimits map[string]int `json:"wip_limits,omitempty"`
+
+	// Backend selects where tasks are stored: sqlite or files.
+	Backend string `json:"backend,omitempty"`
+
+	// Timeout bounds how long a command may spend on the task store, as a
+	// duration such as "30s" or "2m". "0" disables the limit.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Webhooks are told about task changes; see Webhook.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// Webhook is an HTTP endpoint that receives a signed JSON POST for each
+// task change matching its filters. Empty filters match everything. Exported
+type Webhook struct {
+	URL string `json:"url"`
+
+	// Secret signs the payloads, so the receiver can check they came from
+	// taskly; see the webhook package.
+	Secret string `json:"secret,omitempty"`
+
+	// Events limits the change types reported: created, updated, deleted.
+	Events []string `json:"events,omitempty"`
+
+	// Project only reports changes to tasks in this project.
+	Project string `json:"project,omitempty"`
+
+	// Status only reports changes to tasks in this status; for updates,
+	// only those that changed the status to it.
+	Status string `json:"status,omitempty"`
+}
+
+// Validate checks that w has an absolute http or https URL and valid
+// filters. Exported
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL %q must be an absolute http or https URL", w.URL)
+	}
+	for _, event := range w.Events {
+		switch task.ChangeType(event) {
+		case task.Created, task.Updated, task.Deleted:
+		default:
+			return fmt.Errorf("webhook %s: unknown event %q (valid: created, updated, deleted)", w.URL, event)
+		}
+	}
+	if w.Status != "" {
+		if _, err := task.ParseStatus(w.Status); err != nil {
+			return fmt.Errorf("webhook %s: %w", w.URL, err)
+		}
+	}
+	return nil
+}
+
+// OperationTimeout returns the parsed Timeout setting.
+func (c Config) OperationTimeout() (time.Duration, error) {
+	return ParseTimeout(c.Timeout)
+}
+
+// WIPLimit returns the work-in-progress limit for a status, if one is set.
+func (c Config) WIPLimit(status string) (int, bool) {
+	limit, ok := c.WIPLimits[status]
+	return limit, ok
+}
+
+// Default returns the configuration used when no config file exists. Exported
+func Default() Config {
+	return Config{
+		DateFormat: "local",
+		Backend:    BackendSQLite,
+		Timeout:    "30s",
+	}
+}
+
+// GetConfigPath returns the location of the config file. Exported
+func GetConfigPath() (string, error) {
+	scope := gap.NewScope(gap.User, "taskly")
+	path, err := scope.ConfigPath("config.json")
+	if err != nil {
+		return "", fmt.Errorf("could not determine config path: %w", err)
+	}
+	return path, nil
+}
+
+// GetHooksDir returns the directory holding hook scripts, next to the
+// config file. It may not exist. Exported
+func GetHooksDir() (string, error) {
+	path, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "hooks"), nil
+}
+
+// Load reads the config file, filling unset fields with defaults. A missing
+// file is not an error. Exported
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, err := GetConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config file '%s': %w", path, err)
+	}
+
+	var fileCfg Config
+	if err := json.Unmarshal(data, &fileCfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file '%s': %w", path, err)
+	}
+	if fileCfg.DateFormat != "" {
+		cfg.DateFormat = fileCfg.DateFormat
+	}
+	for status, limit := range fileCfg.WIPLimits {
+		if _, err := task.ParseStatus(status); err != nil {
+			return cfg, fmt.Errorf("invalid wip_limits in '%s': %w", path, err)
+		}
+		if limit < 1 {
+			return cfg, fmt.Errorf("invalid wip_limits in '%s': limit for %q must be at least 1", path, status)
+		}
+	}
+	cfg.WIPLimits = fileCfg.WIPLimits
+	if fileCfg.Backend != "" {
+		if err := ValidateBackend(fileCfg.Backend); err != nil {
+			return cfg, fmt.Errorf("invalid backend in '%s': %w", path, err)
+		}
+		cfg.Backend = fileCfg.Backend
+	}
+	if fileCfg.Timeout != "" {
+		if _, err := ParseTimeout(fileCfg.Timeout); err != nil {
+			return cfg, fmt.Errorf("invalid timeout in '%s': %w", path, err)
+		}
+		cfg.Timeout = fileCfg.Timeout
+	}
+	for _, hook := range fileCfg.Webhooks {
+		if err := hook.Validate(); err != nil {
+			return cfg, fmt.Errorf("invalid webhooks in '%s': %w", path, err)
+		}
+	}
+	cfg.Webhooks = fileCfg.Webhooks
+	return cfg, nil
+}
+
+// ValidateBackend checks that name is a known storage backend. Exported
+func ValidateBackend(name string) error {
+	for _, b := range Backends() {
+		if name == b {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown backend %q (valid: %s)", name, strings.Join(Backends(), ", "))
+}
+
+// ParseTimeout parses a timeout such as "30s" or "2m". Zero means no limit;
+// negative durations are rejected. Exported
+func ParseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: use a duration such as 30s or 2m", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// autoBackupKeep is how many automatic backups are kept; older ones are
+// removed after each new automatic backup.
+const autoBackupKeep = 10
+
+// autoBackupPrefix marks backups taken automatically, so rotation never
+// touches backups the user asked for.
+const autoBackupPrefix = "auto-"
+
+// restoreStepPages is how many pages RestoreBackup copies between checks
+// for cancellation.
+const restoreStepPages = 256
+
+// backupTimeLayout names backup files so they sort chronologically.
+const backupTimeLayout = "20060102-150405.000"
+
+// GetBackupDir returns the directory holding backups of the database,
+// creating it if needed. Exported
+func GetBackupDir() (string, error) {
+	dataDir, err := setupPath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(dataDir, "backups")
+	if err := initTaskDir(dir); err != nil {
+		return "", fmt.Errorf("could not initialize backup directory '%s': %w", dir, err)
+	}
+	return dir, nil
+}
+
+// DefaultBackupPath returns a new timestamped path in the backup directory. Exported
+func DefaultBackupPath() (string, error) {
+	dir, err := GetBackupDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("tasks-%s.db", time.Now().Format(backupTimeLayout))
+	return filepath.Join(dir, name), nil
+}
+
+// Backup writes a consistent copy of the database to path using VACUUM INTO,
+// which is safe while other processes are writing. The file must not exist. Exported
+func (tdb *TaskDB) Backup(path string) error {
+	return tdb.BackupContext(context.Background(), path)
+}
+
+// BackupContext is like Backup but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) BackupContext(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return Errorf(ErrConflict, "backup file '%s' already exists", path)
+	}
+	if err := initTaskDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("could not create directory for backup '%s': %w", path, err)
+	}
+	if _, err := tdb.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("backup to '%s' failed: %w", path, err)
+	}
+	return nil
+}
+
+// AutoBackup takes a backup before a risky operation, named after reason,
+// and removes all but the newest autoBackupKeep automatic backups. It
+// returns the path of the new backup. Exported
+func (tdb *TaskDB) AutoBackup(reason string) (string, error) {
+	return tdb.AutoBackupContext(context.Background(), reason)
+}
+
+// AutoBackupContext is like AutoBackup but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) AutoBackupContext(ctx context.Context, reason string) (string, error) {
+	dir, err := GetBackupDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s%s-%s.db", autoBackupPrefix, time.Now().Format(backupTimeLayout), reason)
+	path := filepath.Join(dir, name)
+	if err := tdb.BackupContext(ctx, path); err != nil {
+		return "", err
+	}
+	if err := pruneAutoBackups(dir, autoBackupKeep); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// pruneAutoBackups deletes the oldest automatic backups in dir until at most
+// keep remain. (Unexported)
+func pruneAutoBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed listing backups: %w", err)
+	}
+	var autos []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), autoBackupPrefix) && strings.HasSuffix(e.Name(), ".db") {
+			autos = append(autos, e.Name())
+		}
+	}
+	sort.Strings(autos)
+	for len(autos) > keep {
+		if err := os.Remove(filepath.Join(dir, autos[0])); err != nil {
+			return fmt.Errorf("failed removing old backup: %w", err)
+		}
+		autos = autos[1:]
+	}
+	return nil
+}
+
+// VerifyBackup checks that path is an intact taskly database that this
+// version can read: it must pass PRAGMA integrity_check, contain the tasks
+// table, and not have a newer schema than this build knows. Exported
+func VerifyBackup(path string) error {
+	return VerifyBackupContext(context.Background(), path)
+}
+
+// VerifyBackupContext is like VerifyBackup but uses ctx for cancellation and deadlines.
+func VerifyBackupContext(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return Errorf(ErrNotFound, "cannot read backup: %w", err)
+	}
+	src, err := openReadOnly(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup '%s': %w", path, err)
+	}
+	defer src.Close()
+	return verifyDatabase(ctx, src)
+}
+
+// openReadOnly opens the SQLite database at path without write access. The
+// path is escaped into a file: URI so characters such as '?', '#' and '%'
+// in directory or file names are not taken for URI syntax. (Unexported)
+func openReadOnly(path string) (*sql.DB, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	abs = filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		// Windows paths such as C:/x need a leading slash in a URI.
+		abs = "/" + abs
+	}
+	uri := url.URL{Scheme: "file", Path: abs, RawQuery: "mode=ro"}
+	return sql.Open("sqlite3", uri.String())
+}
+
+// verifyDatabase runs the checks described in VerifyBackup. (Unexported)
+func verifyDatabase(ctx context.Context, conn *sql.DB) error {
+	var result string
+	if err := conn.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return Errorf(ErrInvalidInput, "not a readable SQLite database: %w", err)
+	}
+	if result != "ok" {
+		return Errorf(ErrInvalidInput, "integrity check failed: %s", result)
+	}
+
+	check := &TaskDB{db: conn}
+	exists, err := check.tableExists(ctx, "tasks")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return Errorf(ErrInvalidInput, "not a taskly database: no 'tasks' table")
+	}
+	version, err := check.SchemaVersionContext(ctx)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return Errorf(ErrInvalidInput, "database schema version %d is newer than this version of taskly supports (%d)", version, len(migrations))
+	}
+	return nil
+}
+
+// RestoreBackup verifies the backup at path and replaces the contents of the
+// database with it using SQLite's online backup API, then applies any
+// migrations the backup is missing. Exported
+func (tdb *TaskDB) RestoreBackup(path string) error {
+	return tdb.RestoreBackupContext(context.Background(), path)
+}
+
+// RestoreBackupContext is like RestoreBackup but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) RestoreBackupContext(ctx context.Context, path string) error {
+	if err := VerifyBackupContext(ctx, path); err != nil {
+		return err
+	}
+
+	src, err := openReadOnly(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup '%s': %w", path, err)
+	}
+	defer src.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to backup '%s': %w", path, err)
+	}
+	defer srcConn.Close()
+	destConn, err := tdb.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer destConn.Close()
+
+	err = destConn.Raw(func(destRaw interface{}) error {
+		return srcConn.Raw(func(srcRaw interface{}) error {
+			dest, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected database driver connection %T", destRaw)
+			}
+			source, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected backup driver connection %T", srcRaw)
+			}
+			backup, err := dest.Backup("main", source, "main")
+			if err != nil {
+				return err
+			}
+			// Copy in chunks so a cancelled context stops the restore.
+			for {
+				if err := ctx.Err(); err != nil {
+					backup.Finish()
+					return err
+				}
+				done, err := backup.Step(restoreStepPages)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					return backup.Finish()
+				}
+			}
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("restore from '%s' failed: %w", path, err)
+	}
+	if err := tdb.migrate(ctx); err != nil {
+		return fmt.Errorf("failed to migrate restored database: %w", err)
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,9 +16,14 @@ import (
 	"github.com/ashish0kumar/taskly/internal/task"
 )
 
+// busyTimeout is how long a statement waits for another process's lock
+// before giving up.
+const busyTimeout = 5 * time.Second
+
 // TaskDB holds the database connection. Exported type.
 type TaskDB struct {
-	db *sql.DB
+	db     *sql.DB
+	router Router // Queues webhook deliveries for changes; see SetRouter
 }
 
 // setupPath determines the application's data directory path. (Unexported)
@@ -69,8 +76,19 @@ func OpenDB() (*TaskDB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not get storage path: %w", err)
 	}
+	return OpenPath(dbPath)
+}
 
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+// OpenPath opens the database file at dbPath, creating and migrating it as
+// needed. Exported
+func OpenPath(dbPath string) (*TaskDB, error) {
+	// WAL lets readers proceed while another process writes, and the busy
+	// timeout makes a writer wait for a lock instead of failing at once with
+	// "database is locked". Transactions take the write lock when they
+	// begin, so one that reads before writing waits for the lock too rather
+	// than failing when another process wrote in between.
+	dsn := fmt.Sprintf("%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", dbPath, busyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database '%s': %w", dbPath, err)
 	}
@@ -80,19 +98,38 @@ func OpenDB() (*TaskDB, error) {
 	}
 
 	t := &TaskDB{db: db} // Create exported TaskDB
+	ctx := context.Background()
 
-	exists, err := t.tableExists("tasks")
+	exists, err := t.tableExists(ctx, "tasks")
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to check if 'tasks' table exists: %w", err)
 	}
 	if !exists {
-		err := t.createTable()
+		err := t.createTable(ctx)
 		if err != nil {
 			db.Close()
 			return nil, fmt.Errorf("failed to create 'tasks' table: %w", err)
 		}
 	}
+	if exists {
+		// Keep a copy of the database as it was before changing its schema.
+		version, err := t.SchemaVersionContext(ctx)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if version < len(migrations) {
+			if _, err := t.AutoBackupContext(ctx, "pre-migration"); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to back up database before migrating: %w", err)
+			}
+		}
+	}
+	if err := t.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
 	return t, nil
 }
 
@@ -105,10 +142,10 @@ func (tdb *TaskDB) Close() error {
 }
 
 // tableExists checks if the 'tasks' table exists. (Unexported)
-func (tdb *TaskDB) tableExists(name string) (bool, error) {
+func (tdb *TaskDB) tableExists(ctx context.Context, name string) (bool, error) {
 	query := "SELECT name FROM sqlite_master WHERE type='table' AND name=?;"
 	var tableName string
-	err := tdb.db.QueryRow(query, name).Scan(&tableName)
+	err := tdb.db.QueryRowContext(ctx, query, name).Scan(&tableName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -119,7 +156,7 @@ func (tdb *TaskDB) tableExists(name string) (bool, error) {
 }
 
 // createTable creates the 'tasks' table. (Unexported)
-func (tdb *TaskDB) createTable() error {
+func (tdb *TaskDB) createTable(ctx context.Context) error {
 	schema := `
 	CREATE TABLE "tasks" (
 		"id" INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -128,7 +165,7 @@ func (tdb *TaskDB) createTable() error {
 		"status" TEXT NOT NULL DEFAULT 'todo' CHECK(status IN ('todo', 'in progress', 'done')),
 		"created" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	)`
-	_, err := tdb.db.Exec(schema)
+	_, err := tdb.db.ExecContext(ctx, schema)
 	return err
 }
 
@@ -136,141 +173,342 @@ func (tdb *TaskDB) createTable() error {
 
 // Insert adds a new task.
 func (tdb *TaskDB) Insert(name, project string) (task.Task, error) {
-	createdTime := time.Now()
-	defaultStatus := task.Todo.String() // Use Status enum from task package
+	return tdb.InsertContext(context.Background(), name, project)
+}
+
+// InsertContext is like Insert but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) InsertContext(ctx context.Context, name, project string) (task.Task, error) {
+	return tdb.InsertTaskContext(ctx, task.Task{Name: name, Project: project})
+}
 
-	stmt := "INSERT INTO tasks(name, project, status, created) VALUES(?, ?, ?, ?)"
-	res, err := tdb.db.Exec(stmt, name, project, defaultStatus, createdTime)
+// InsertTask adds a new task with the name, project, status and tags of t,
+// which starts as todo if t has no status.
+func (tdb *TaskDB) InsertTask(t task.Task) (task.Task, error) {
+	return tdb.InsertTaskContext(context.Background(), t)
+}
+
+// InsertTaskContext is like InsertTask but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) InsertTaskContext(ctx context.Context, t task.Task) (task.Task, error) {
+	// Store UTC so values match SQLite's CURRENT_TIMESTAMP and compare consistently.
+	t, err := PrepareInsert(t, time.Now().UTC())
 	if err != nil {
-		return task.Task{}, fmt.Errorf("insert failed: %w", err)
+		return task.Task{}, err
 	}
-	id, err := res.LastInsertId()
+
+	err = tdb.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := insertTask(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+		if t, err = getTask(ctx, tx, id); err != nil {
+			return err
+		}
+		return tdb.record(ctx, tx, task.Change{Type: task.Created, Task: t})
+	})
 	if err != nil {
-		return task.Task{}, fmt.Errorf("failed to get last insert ID: %w", err)
+		return task.Task{}, err
 	}
-	return task.Task{
-		ID:      uint(id),
-		Name:    name,
-		Project: project,
-		Status:  defaultStatus,
-		Created: createdTime,
-	}, nil
+	return t, nil
 }
 
-// Delete removes a task by ID.
-func (tdb *TaskDB) Delete(id uint) error {
-	res, err := tdb.db.Exec("DELETE FROM tasks WHERE id = ?", id)
+// execer is satisfied by both *sql.DB and *sql.Tx. (Unexported)
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertTask stores t as a new task ranked after all existing ones and
+// returns its ID. (Unexported)
+func insertTask(ctx context.Context, ex execer, t task.Task) (uint, error) {
+	stmt := `INSERT INTO tasks(name, project, status, created, updated, completed, tags, due, position)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + ? FROM tasks))`
+	res, err := ex.ExecContext(ctx, stmt, t.Name, t.Project, t.Status, t.Created.UTC(), t.Updated.UTC(), nullTime(t.Completed), joinTags(t.Tags), nullDue(t.Due), positionGap)
 	if err != nil {
-		return fmt.Errorf("delete failed for id %d: %w", id, err)
+		return 0, fmt.Errorf("insert failed: %w", classify(err))
 	}
-	rowsAffected, err := res.RowsAffected()
-	if err == nil && rowsAffected == 0 {
-		return fmt.Errorf("task with ID %d not found for deletion", id)
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
 	}
-	return err
+	return uint(id), nil
+}
+
+// Restore re-inserts a previously deleted task, keeping its ID and timestamps.
+func (tdb *TaskDB) Restore(t task.Task) error {
+	return tdb.RestoreContext(context.Background(), t)
+}
+
+// RestoreContext is like Restore but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) RestoreContext(ctx context.Context, t task.Task) error {
+	stmt := "INSERT INTO tasks(id, name, project, status, created, updated, completed, tags, due, position) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	return tdb.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt, t.ID, t.Name, t.Project, t.Status, t.Created.UTC(), t.Updated.UTC(), nullTime(t.Completed), joinTags(task.NormalizeTags(t.Tags)), nullDue(task.Day(t.Due)), t.Position)
+		if err != nil {
+			return fmt.Errorf("restore failed for id %d: %w", t.ID, classify(err))
+		}
+		restored, err := getTask(ctx, tx, t.ID)
+		if err != nil {
+			return err
+		}
+		return tdb.record(ctx, tx, task.Change{Type: task.Created, Task: restored})
+	})
+}
+
+// Delete removes a task by ID.
+func (tdb *TaskDB) Delete(id uint) error {
+	return tdb.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) DeleteContext(ctx context.Context, id uint) error {
+	return tdb.deleteTask(ctx, id, time.Time{})
+}
+
+// DeleteIfUpdated removes a task by ID if it was last updated at updated,
+// and fails with ErrConflict otherwise, so a deletion based on a stale copy
+// of the task cannot remove someone else's changes.
+func (tdb *TaskDB) DeleteIfUpdated(id uint, updated time.Time) error {
+	return tdb.DeleteIfUpdatedContext(context.Background(), id, updated)
+}
+
+// DeleteIfUpdatedContext is like DeleteIfUpdated but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) DeleteIfUpdatedContext(ctx context.Context, id uint, updated time.Time) error {
+	return tdb.deleteTask(ctx, id, updated)
+}
+
+// deleteTask removes a task by ID, if it was last updated at ifUpdated
+// unless that is zero. (Unexported)
+func (tdb *TaskDB) deleteTask(ctx context.Context, id uint, ifUpdated time.Time) error {
+	return tdb.inTx(ctx, func(tx *sql.Tx) error {
+		t, err := getTask(ctx, tx, id)
+		if errors.Is(err, ErrNotFound) {
+			return Errorf(ErrNotFound, "task with ID %d not found for deletion", id)
+		} else if err != nil {
+			return err
+		}
+		if err := CheckUnchanged(t, Changes{IfUpdated: ifUpdated}); err != nil {
+			return err
+		}
+		query, args := "DELETE FROM tasks WHERE id = ?", []interface{}{id}
+		if !ifUpdated.IsZero() {
+			query += " AND " + unchangedSince
+			args = append(args, t.Updated.UTC())
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("delete failed for id %d: %w", id, classify(err))
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return Errorf(ErrConflict, "task %d was changed while deleting it", id)
+		}
+		return tdb.record(ctx, tx, task.Change{Type: task.Deleted, Task: t})
+	})
 }
 
 // Update modifies an existing task.
-func (tdb *TaskDB) Update(id uint, name *string, project *string, status *string) (task.Task, error) {
-	orig, err := tdb.GetTask(id) // Use exported GetTask
+func (tdb *TaskDB) Update(id uint, ch Changes) (task.Task, error) {
+	return tdb.UpdateContext(context.Background(), id, ch)
+}
+
+// UpdateContext is like Update but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) UpdateContext(ctx context.Context, id uint, ch Changes) (task.Task, error) {
+	var updated task.Task
+	err := tdb.inTx(ctx, func(tx *sql.Tx) error {
+		orig, err := getTask(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("cannot update task %d: %w", id, err)
+		}
+		if err := CheckUnchanged(orig, ch); err != nil {
+			return err
+		}
+		if err := validateUpdate(ch); err != nil {
+			return err
+		}
+		updated = orig
+		now := time.Now().UTC()
+		setClauses := []string{}
+		args := []interface{}{}
+		if ch.Name != nil {
+			setClauses = append(setClauses, "name = ?")
+			args = append(args, *ch.Name)
+			updated.Name = *ch.Name
+		}
+		if ch.Project != nil {
+			setClauses = append(setClauses, "project = ?")
+			args = append(args, *ch.Project)
+			updated.Project = *ch.Project
+		}
+		if ch.Status != nil {
+			// Record when a task is finished; reopening it clears the timestamp.
+			if *ch.Status != orig.Status {
+				if *ch.Status == task.Done.String() {
+					updated.Completed = now
+				} else {
+					updated.Completed = time.Time{}
+				}
+				setClauses = append(setClauses, "completed = ?")
+				args = append(args, nullTime(updated.Completed))
+			}
+			setClauses = append(setClauses, "status = ?")
+			args = append(args, *ch.Status)
+			updated.Status = *ch.Status
+		}
+		if ch.Tags != nil {
+			updated.Tags = task.NormalizeTags(*ch.Tags)
+			setClauses = append(setClauses, "tags = ?")
+			args = append(args, joinTags(updated.Tags))
+		}
+		if ch.Due != nil {
+			updated.Due = task.Day(*ch.Due)
+			setClauses = append(setClauses, "due = ?")
+			args = append(args, nullDue(updated.Due))
+		}
+		if len(setClauses) == 0 {
+			return nil
+		}
+		updated.Updated = now
+		setClauses = append(setClauses, "updated = ?")
+		args = append(args, updated.Updated, id)
+		where := "id = ?"
+		if !ch.IfUpdated.IsZero() {
+			// Repeat the check in the write itself, which is atomic.
+			where += " AND " + unchangedSince
+			args = append(args, orig.Updated.UTC())
+		}
+		query := fmt.Sprintf("UPDATE tasks SET %s WHERE %s", strings.Join(setClauses, ", "), where)
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("db update failed for id %d: %w", id, classify(err))
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return Errorf(ErrConflict, "task %d was changed while updating it", id)
+		}
+		return tdb.record(ctx, tx, task.Change{Type: task.Updated, Task: updated, Previous: orig})
+	})
 	if err != nil {
-		return task.Task{}, fmt.Errorf("cannot update task %d: %w", id, err)
+		return task.Task{}, err
 	}
-	setClauses := []string{}
-	args := []interface{}{}
-	if name != nil {
-		setClauses = append(setClauses, "name = ?")
-		args = append(args, *name)
-		orig.Name = *name
-	}
-	if project != nil {
-		setClauses = append(setClauses, "project = ?")
-		args = append(args, *project)
-		orig.Project = *project
+	return updated, nil
+}
+
+// unchangedSince is the condition that a task row was last updated at the
+// time given as its parameter. The times are compared as instants, as the
+// stored text may differ from the parameter's: migration 2 wrote every
+// timestamp with three fractional digits, which go-sqlite3 omits when
+// zero. (Unexported)
+const unchangedSince = "julianday(COALESCE(updated, created)) = julianday(?)"
+
+// taskColumns lists the columns read by scanTask, in scan order.
+const taskColumns = "id, name, project, status, created, updated, completed, position, tags, due"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows. (Unexported)
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask reads a single task row selected with taskColumns. (Unexported)
+func scanTask(row rowScanner) (task.Task, error) {
+	var t task.Task
+	var project sql.NullString
+	var updated, completed sql.NullTime
+	var tags string
+	var due sql.NullString
+	if err := row.Scan(&t.ID, &t.Name, &project, &t.Status, &t.Created, &updated, &completed, &t.Position, &tags, &due); err != nil {
+		return task.Task{}, err
 	}
-	if status != nil {
-		setClauses = append(setClauses, "status = ?")
-		args = append(args, *status)
-		orig.Status = *status
+	t.Tags = strings.Fields(tags)
+	if due.Valid {
+		d, err := time.Parse(task.DueLayout, due.String)
+		if err != nil {
+			return task.Task{}, fmt.Errorf("invalid due date %q: %w", due.String, err)
+		}
+		t.Due = d
 	}
-	if len(setClauses) == 0 {
-		return orig, nil
+	if project.Valid {
+		t.Project = project.String
 	}
-	args = append(args, id)
-	query := fmt.Sprintf("UPDATE tasks SET %s WHERE id = ?", strings.Join(setClauses, ", "))
-	res, err := tdb.db.Exec(query, args...)
-	if err != nil {
-		return task.Task{}, fmt.Errorf("db update failed for id %d: %w", id, err)
+	if updated.Valid {
+		t.Updated = updated.Time
+	} else {
+		t.Updated = t.Created
 	}
-	rowsAffected, err := res.RowsAffected()
-	if err == nil && rowsAffected == 0 {
-		return task.Task{}, fmt.Errorf("task with ID %d not found for update", id)
+	if completed.Valid {
+		t.Completed = completed.Time
 	}
-	return orig, nil
+	return t, nil
 }
 
-// GetTasks retrieves all tasks.
-func (tdb *TaskDB) GetTasks() ([]task.Task, error) {
-	tasks := []task.Task{}
-	rows, err := tdb.db.Query("SELECT id, name, project, status, created FROM tasks ORDER BY created ASC")
-	if err != nil {
-		return nil, fmt.Errorf("unable to query tasks: %w", err)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var t task.Task
-		var project sql.NullString
-		err = rows.Scan(&t.ID, &t.Name, &project, &t.Status, &t.Created)
-		if err != nil {
-			return nil, fmt.Errorf("failed scanning task row: %w", err)
-		}
-		if project.Valid {
-			t.Project = project.String
-		}
-		tasks = append(tasks, t)
+// joinTags converts normalised tags to the form stored in the tags column. (Unexported)
+func joinTags(tags []string) string {
+	return strings.Join(tags, " ")
+}
+
+// nullDue converts a due date to the YYYY-MM-DD text stored in the due
+// column, or SQL NULL for none. (Unexported)
+func nullDue(due time.Time) interface{} {
+	if due.IsZero() {
+		return nil
 	}
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating task rows: %w", err)
+	return task.FormatDue(due)
+}
+
+// nullTime converts a zero time to SQL NULL and anything else to UTC. (Unexported)
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
 	}
-	return tasks, nil
+	return t.UTC()
+}
+
+// GetTasks retrieves all tasks, ordered by manual rank within status.
+func (tdb *TaskDB) GetTasks() ([]task.Task, error) {
+	return tdb.GetTasksContext(context.Background())
+}
+
+// GetTasksContext is like GetTasks but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) GetTasksContext(ctx context.Context) ([]task.Task, error) {
+	return tdb.ListTasksContext(ctx, ListOptions{})
 }
 
 // GetTask retrieves a single task by ID.
 func (tdb *TaskDB) GetTask(id uint) (task.Task, error) {
-	var t task.Task
-	var project sql.NullString
-	err := tdb.db.QueryRow("SELECT id, name, project, status, created FROM tasks WHERE id = ?", id).
-		Scan(&t.ID, &t.Name, &project, &t.Status, &t.Created)
+	return tdb.GetTaskContext(context.Background(), id)
+}
+
+// GetTaskContext is like GetTask but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) GetTaskContext(ctx context.Context, id uint) (task.Task, error) {
+	return getTask(ctx, tdb.db, id)
+}
+
+// getTask reads a single task by ID. (Unexported)
+func getTask(ctx context.Context, q querier, id uint) (task.Task, error) {
+	t, err := scanTask(q.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return task.Task{}, fmt.Errorf("task with ID %d not found", id)
+			return task.Task{}, Errorf(ErrNotFound, "task with ID %d not found", id)
 		}
 		return task.Task{}, fmt.Errorf("failed querying task %d: %w", id, err)
 	}
-	if project.Valid {
-		t.Project = project.String
-	}
 	return t, nil
 }
 
 // GetTasksByStatus retrieves tasks filtered by status.
 func (tdb *TaskDB) GetTasksByStatus(status string) ([]task.Task, error) {
+	return tdb.GetTasksByStatusContext(context.Background(), status)
+}
+
+// GetTasksByStatusContext is like GetTasksByStatus but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) GetTasksByStatusContext(ctx context.Context, status string) ([]task.Task, error) {
 	tasks := []task.Task{}
-	rows, err := tdb.db.Query("SELECT id, name, project, status, created FROM tasks WHERE status = ? ORDER BY created ASC", status)
+	rows, err := tdb.db.QueryContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE status = ? ORDER BY created ASC", status)
 	if err != nil {
 		return nil, fmt.Errorf("unable to query tasks by status %q: %w", status, err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var t task.Task
-		var project sql.NullString
-		err = rows.Scan(&t.ID, &t.Name, &project, &t.Status, &t.Created)
+		t, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed scanning task row (by status): %w", err)
 		}
-		if project.Valid {
-			t.Project = project.String
-		}
 		tasks = append(tasks, t)
 	}
 	if err = rows.Err(); err != nil {
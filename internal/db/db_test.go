@@ -0,0 +1,104 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/db/storetest"
+)
+
+func TestTaskDBConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store {
+		tdb, err := db.OpenPath(filepath.Join(t.TempDir(), "tasks.db"))
+		if err != nil {
+			t.Fatalf("OpenPath: %v", err)
+		}
+		t.Cleanup(func() { tdb.Close() })
+		return tdb
+	})
+}
+
+func TestBackupPathWithURICharacters(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "odd dir #1 100%")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tdb, err := db.OpenPath(filepath.Join(dir, "tasks.db"))
+	if err != nil {
+		t.Fatalf("OpenPath: %v", err)
+	}
+	defer tdb.Close()
+	ctx := context.Background()
+	if _, err := tdb.InsertContext(ctx, "Keep me", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := filepath.Join(dir, "backup?mode=rwc.db")
+	if err := tdb.BackupContext(ctx, backup); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := db.VerifyBackupContext(ctx, backup); err != nil {
+		t.Fatalf("VerifyBackup: %v", err)
+	}
+	if err := tdb.DeleteContext(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := tdb.RestoreBackupContext(ctx, backup); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if _, err := tdb.GetTaskContext(ctx, 1); err != nil {
+		t.Errorf("task missing after restore: %v", err)
+	}
+	// An unescaped '?' would have made SQLite open a file named "backup".
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != filepath.Base(backup) && !strings.HasPrefix(e.Name(), "tasks.db") {
+			t.Errorf("stray file %q created next to the backup", e.Name())
+		}
+	}
+}
+
+func TestConditionalUpdateOfMigratedTimestamps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	tdb, err := db.OpenPath(path)
+	if err != nil {
+		t.Fatalf("OpenPath: %v", err)
+	}
+	defer tdb.Close()
+	ctx := context.Background()
+	if _, err := tdb.InsertContext(ctx, "Old", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tdb.InsertContext(ctx, "Older", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the timestamps as migration 2 did: whole seconds and
+	// milliseconds, always with three fractional digits.
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	for id, ts := range map[int]string{1: "2024-01-02 03:04:05", 2: "2024-01-02 03:04:05.120"} {
+		if _, err := raw.Exec("UPDATE tasks SET updated = strftime('%Y-%m-%d %H:%M:%f+00:00', ?) WHERE id = ?", ts, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id := uint(1); id <= 2; id++ {
+		current, err := tdb.GetTaskContext(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "Renamed"
+		if _, err := tdb.UpdateContext(ctx, id, db.Changes{Name: &name, IfUpdated: current.Updated}); err != nil {
+			t.Errorf("conditional update of task %d last updated %v: %v", id, current.Updated, err)
+		}
+	}
+}
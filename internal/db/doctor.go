@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// CheckResult is the outcome of one health check run by Check. Exported
+type CheckResult struct {
+	Name     string
+	Problems []string
+	// Repairable reports whether Repair can reliably fix the problems found.
+	// Repair still rebuilds indexes, which fixes some integrity failures.
+	Repairable bool
+}
+
+// OK reports whether the check found no problems.
+func (c CheckResult) OK() bool { return len(c.Problems) == 0 }
+
+// HealthReport collects the results of Check. Exported
+type HealthReport struct {
+	SchemaVersion       int
+	LatestSchemaVersion int
+	Checks              []CheckResult
+}
+
+// OK reports whether every check passed.
+func (r HealthReport) OK() bool {
+	for _, c := range r.Checks {
+		if !c.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// maxReportedProblems caps how many problems a single check lists.
+const maxReportedProblems = 20
+
+// Check inspects the database for corruption and inconsistent task data
+// without changing anything. Exported
+func (tdb *TaskDB) Check() (HealthReport, error) {
+	return tdb.CheckContext(context.Background())
+}
+
+// CheckContext is like Check but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) CheckContext(ctx context.Context) (HealthReport, error) {
+	report := HealthReport{LatestSchemaVersion: len(migrations)}
+	version, err := tdb.SchemaVersionContext(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.SchemaVersion = version
+
+	checks := []struct {
+		name       string
+		repairable bool
+		run        func(ctx context.Context) ([]string, error)
+	}{
+		{"integrity", false, tdb.checkIntegrity},
+		{"foreign keys", false, tdb.checkForeignKeys},
+		{"status values", true, tdb.checkStatuses},
+		{"completion times", true, tdb.checkCompleted},
+	}
+	for _, c := range checks {
+		problems, err := c.run(ctx)
+		if err != nil {
+			return report, fmt.Errorf("%s check failed: %w", c.name, err)
+		}
+		report.Checks = append(report.Checks, CheckResult{Name: c.name, Problems: problems, Repairable: c.repairable})
+	}
+	return report, nil
+}
+
+// checkIntegrity runs PRAGMA integrity_check. (Unexported)
+func (tdb *TaskDB) checkIntegrity(ctx context.Context) ([]string, error) {
+	problems, err := tdb.queryStrings(ctx, fmt.Sprintf("PRAGMA integrity_check(%d)", maxReportedProblems))
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) == 1 && problems[0] == "ok" {
+		return nil, nil
+	}
+	return problems, nil
+}
+
+// checkForeignKeys runs PRAGMA foreign_key_check, which reports rows whose
+// parent row is missing in any table that declares a foreign key. The tasks
+// table has none today, so this only finds problems in tables added later. (Unexported)
+func (tdb *TaskDB) checkForeignKeys(ctx context.Context) ([]string, error) {
+	rows, err := tdb.db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return nil, err
+		}
+		if len(problems) < maxReportedProblems {
+			problems = append(problems, fmt.Sprintf("%s row %d references a missing %s row", table, rowid.Int64, parent))
+		}
+	}
+	return problems, rows.Err()
+}
+
+// checkStatuses finds tasks whose status is not a known task.Status. (Unexported)
+func (tdb *TaskDB) checkStatuses(ctx context.Context) ([]string, error) {
+	rows, err := tdb.db.QueryContext(ctx, "SELECT id, status FROM tasks WHERE status NOT IN (?, ?, ?) ORDER BY id LIMIT ?",
+		task.Todo.String(), task.InProgress.String(), task.Done.String(), maxReportedProblems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var id uint
+		var status sql.NullString
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, err
+		}
+		problems = append(problems, fmt.Sprintf("task %d has unknown status %q", id, status.String))
+	}
+	return problems, rows.Err()
+}
+
+// checkCompleted finds done tasks without a completion time and open tasks
+// with one. (Unexported)
+func (tdb *TaskDB) checkCompleted(ctx context.Context) ([]string, error) {
+	rows, err := tdb.db.QueryContext(ctx, `SELECT id, status = ? FROM tasks
+		WHERE (status = ?) != (completed IS NOT NULL) ORDER BY id LIMIT ?`,
+		task.Done.String(), task.Done.String(), maxReportedProblems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var id uint
+		var done bool
+		if err := rows.Scan(&id, &done); err != nil {
+			return nil, err
+		}
+		if done {
+			problems = append(problems, fmt.Sprintf("task %d is done but has no completion time", id))
+		} else {
+			problems = append(problems, fmt.Sprintf("task %d is not done but has a completion time", id))
+		}
+	}
+	return problems, rows.Err()
+}
+
+// queryStrings returns the first column of every row of query. (Unexported)
+func (tdb *TaskDB) queryStrings(ctx context.Context, query string) ([]string, error) {
+	rows, err := tdb.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// Repair fixes what Check can safely fix and describes each action taken:
+// indexes are rebuilt, statuses that differ from a known one only by case or
+// spacing are normalised (others reset to todo), and completion times are
+// made consistent with status. Damaged table data cannot be repaired; restore
+// a backup instead. Exported
+func (tdb *TaskDB) Repair() ([]string, error) {
+	return tdb.RepairContext(context.Background())
+}
+
+// RepairContext is like Repair but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) RepairContext(ctx context.Context) ([]string, error) {
+	var actions []string
+
+	// REINDEX rebuilds every index from table data, fixing index corruption.
+	if _, err := tdb.db.ExecContext(ctx, "REINDEX"); err != nil {
+		return actions, fmt.Errorf("failed to rebuild indexes: %w", err)
+	}
+	actions = append(actions, "rebuilt indexes")
+
+	tx, err := tdb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return actions, fmt.Errorf("failed to begin repair: %w", classify(err))
+	}
+	defer tx.Rollback()
+
+	fixed, err := repairStatuses(ctx, tx)
+	if err != nil {
+		return actions, err
+	}
+	actions = append(actions, fixed...)
+
+	res, err := tx.ExecContext(ctx, "UPDATE tasks SET completed = COALESCE(updated, created) WHERE status = ? AND completed IS NULL", task.Done.String())
+	if err != nil {
+		return actions, fmt.Errorf("failed to repair completion times: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		actions = append(actions, fmt.Sprintf("set missing completion time on %d done task(s)", n))
+	}
+	res, err = tx.ExecContext(ctx, "UPDATE tasks SET completed = NULL WHERE status != ? AND completed IS NOT NULL", task.Done.String())
+	if err != nil {
+		return actions, fmt.Errorf("failed to repair completion times: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		actions = append(actions, fmt.Sprintf("cleared completion time on %d open task(s)", n))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return actions, fmt.Errorf("failed to commit repair: %w", err)
+	}
+	return actions, nil
+}
+
+// repairStatuses maps unknown status values onto known ones. (Unexported)
+func repairStatuses(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id, status FROM tasks WHERE status NOT IN (?, ?, ?)",
+		task.Todo.String(), task.InProgress.String(), task.Done.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task statuses: %w", err)
+	}
+	type fix struct {
+		id       uint
+		from, to string
+	}
+	var fixes []fix
+	for rows.Next() {
+		var id uint
+		var status sql.NullString
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan task status: %w", err)
+		}
+		to := task.Todo.String()
+		if st, err := task.ParseStatus(strings.ToLower(strings.Join(strings.Fields(status.String), " "))); err == nil {
+			to = st.String()
+		}
+		fixes = append(fixes, fix{id: id, from: status.String, to: to})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read task statuses: %w", err)
+	}
+
+	var actions []string
+	for _, f := range fixes {
+		if _, err := tx.ExecContext(ctx, "UPDATE tasks SET status = ? WHERE id = ?", f.to, f.id); err != nil {
+			return nil, fmt.Errorf("failed to repair status of task %d: %w", f.id, err)
+		}
+		actions = append(actions, fmt.Sprintf("changed status of task %d from %q to %q", f.id, f.from, f.to))
+	}
+	return actions, nil
+}
+
+// Vacuum rebuilds the database file, reclaiming unused space. Exported
+func (tdb *TaskDB) Vacuum() error {
+	return tdb.VacuumContext(context.Background())
+}
+
+// VacuumContext is like Vacuum but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) VacuumContext(ctx context.Context) error {
+	if _, err := tdb.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum failed: %w", err)
+	}
+	return nil
+}
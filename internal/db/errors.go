@@ -0,0 +1,55 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// Kinds of failure reported by stores. Errors returned by a Store match at
+// most one of these with errors.Is; anything else is an I/O or internal
+// failure.
+var (
+	ErrNotFound     = errors.New("not found")        // The task does not exist
+	ErrConflict     = errors.New("conflict")         // The change clashes with existing data
+	ErrInvalidInput = errors.New("invalid input")    // The arguments can never succeed as given
+	ErrBusy         = errors.New("database is busy") // Another process holds the lock; retrying may succeed
+)
+
+// kindError tags an error with one of the kinds above without changing its
+// message. (Unexported)
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+// Unwrap lets errors.Is match both the kind and the wrapped error.
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// Errorf formats an error like fmt.Errorf that also matches kind with
+// errors.Is. Exported
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// classify tags SQLite errors that callers can act on: lock timeouts as
+// ErrBusy, uniqueness violations as ErrConflict and failed CHECK or NOT NULL
+// constraints as ErrInvalidInput. Other errors are returned unchanged. (Unexported)
+func classify(err error) error {
+	var sqlErr sqlite3.Error
+	if !errors.As(err, &sqlErr) {
+		return err
+	}
+	switch {
+	case sqlErr.Code == sqlite3.ErrBusy || sqlErr.Code == sqlite3.ErrLocked:
+		return &kindError{kind: ErrBusy, err: err}
+	case sqlErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey || sqlErr.ExtendedCode == sqlite3.ErrConstraintUnique:
+		return &kindError{kind: ErrConflict, err: err}
+	case sqlErr.ExtendedCode == sqlite3.ErrConstraintCheck || sqlErr.ExtendedCode == sqlite3.ErrConstraintNotNull:
+		return &kindError{kind: ErrInvalidInput, err: err}
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// ImportOptions controls ImportTasks. Exported
+type ImportOptions struct {
+	AllowDuplicates bool // Import tasks even if the same name and project already exist
+	DryRun          bool // Roll back instead of committing
+}
+
+// ImportResult describes what ImportTasks did, or would do for a dry run. Exported
+type ImportResult struct {
+	Imported   []task.Task // With the IDs they were (or would have been) given
+	Duplicates []task.Task // Skipped because the same name and project already exist
+}
+
+// ImportTasks adds tasks in a single transaction, so either all of them are
+// stored or none are. Missing statuses default to todo and missing
+// timestamps to now. A task is a duplicate if an existing or earlier
+// imported task has the same project and, ignoring case, the same name.
+func (tdb *TaskDB) ImportTasks(tasks []task.Task, opts ImportOptions) (ImportResult, error) {
+	return tdb.ImportTasksContext(context.Background(), tasks, opts)
+}
+
+// ImportTasksContext is like ImportTasks but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) ImportTasksContext(ctx context.Context, tasks []task.Task, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+
+	tx, err := tdb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin import: %w", classify(err))
+	}
+	defer tx.Rollback()
+
+	seen := map[string]bool{}
+	rows, err := tx.QueryContext(ctx, "SELECT name, COALESCE(project, '') FROM tasks")
+	if err != nil {
+		return result, fmt.Errorf("unable to query existing tasks: %w", err)
+	}
+	for rows.Next() {
+		var name, project string
+		if err := rows.Scan(&name, &project); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed scanning task row: %w", err)
+		}
+		seen[DuplicateKey(name, project)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("error iterating task rows: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for i, t := range tasks {
+		t, err := PrepareImport(t, i, now)
+		if err != nil {
+			return result, err
+		}
+
+		key := DuplicateKey(t.Name, t.Project)
+		if seen[key] && !opts.AllowDuplicates {
+			result.Duplicates = append(result.Duplicates, t)
+			continue
+		}
+		seen[key] = true
+
+		id, err := insertTask(ctx, tx, t)
+		if err != nil {
+			return result, fmt.Errorf("task %d (%q): %w", i+1, t.Name, err)
+		}
+		t.ID = id
+		result.Imported = append(result.Imported, t)
+
+		imported, err := getTask(ctx, tx, id)
+		if err != nil {
+			return result, err
+		}
+		if err := tdb.record(ctx, tx, task.Change{Type: task.Created, Task: imported}); err != nil {
+			return result, err
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit import: %w", classify(err))
+	}
+	return result, nil
+}
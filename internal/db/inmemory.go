@@ -0,0 +1,411 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// This file implements the semantics of TaskDB's queries over plain slices,
+// for Store backends that keep tasks in memory rather than in SQLite.
+
+// Matches reports whether t passes the filters in the options.
+func (o ListOptions) Matches(t task.Task) bool {
+	if o.Project != "" && t.Project != o.Project {
+		return false
+	}
+	if o.Tag != "" && !t.HasTag(o.Tag) {
+		return false
+	}
+	if len(o.Statuses) > 0 {
+		found := false
+		for _, s := range o.Statuses {
+			if t.Status == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if t.Status == task.Done.String() {
+		if o.HideDone {
+			return false
+		}
+		if !o.DoneSince.IsZero() && t.Completed.Before(o.DoneSince) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the tasks matching the options, in the order ListTasks
+// would return them. The input slice is not modified. Exported
+func (o ListOptions) Apply(tasks []task.Task) ([]task.Task, error) {
+	keys := make([]SortKey, 0, len(o.Sort)+2)
+	keys = append(keys, o.Sort...)
+	keys = append(keys, SortKey{Field: "status"}, SortKey{Field: "position"})
+	for _, k := range keys {
+		if _, ok := sortColumns[k.Field]; !ok {
+			return nil, fmt.Errorf("unknown sort field %q", k.Field)
+		}
+	}
+
+	out := []task.Task{}
+	for _, t := range tasks {
+		if o.Matches(t) {
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		for _, k := range keys {
+			c := compareField(out[i], out[j], k.Field)
+			if c == 0 {
+				continue
+			}
+			if k.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// compareField compares two tasks by a sort field the way sortColumns orders
+// them in SQL, returning -1, 0 or 1. (Unexported)
+func compareField(a, b task.Task, field string) int {
+	switch field {
+	case "id":
+		return compareOrdered(a.ID, b.ID)
+	case "name":
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case "project":
+		return strings.Compare(strings.ToLower(a.Project), strings.ToLower(b.Project))
+	case "status":
+		as, _ := task.ParseStatus(a.Status)
+		bs, _ := task.ParseStatus(b.Status)
+		return compareOrdered(as, bs)
+	case "created":
+		return a.Created.Compare(b.Created)
+	case "position":
+		return compareOrdered(a.Position, b.Position)
+	case "updated":
+		return a.Updated.Compare(b.Updated)
+	case "due":
+		// Tasks without a due date come last, as in SQL.
+		if a.Due.IsZero() || b.Due.IsZero() {
+			return compareOrdered(boolInt(a.Due.IsZero()), boolInt(b.Due.IsZero()))
+		}
+		return a.Due.Compare(b.Due)
+	}
+	return 0
+}
+
+// boolInt returns 1 for true and 0 for false. (Unexported)
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// compareOrdered returns -1, 0 or 1 as a is less than, equal to or greater than b. (Unexported)
+func compareOrdered[T ~int | ~uint | ~float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// CountByStatus counts tasks per status, like CountTasksByStatus. Exported
+func CountByStatus(tasks []task.Task) map[string]int {
+	counts := map[string]int{}
+	for _, t := range tasks {
+		counts[t.Status]++
+	}
+	return counts
+}
+
+// SummarizeProjects aggregates tasks per project, like GetProjectSummaries. Exported
+func SummarizeProjects(tasks []task.Task) []ProjectSummary {
+	byProject := map[string]*ProjectSummary{}
+	for _, t := range tasks {
+		s, ok := byProject[t.Project]
+		if !ok {
+			s = &ProjectSummary{Project: t.Project}
+			byProject[t.Project] = s
+		}
+		switch t.Status {
+		case task.Todo.String():
+			s.Todo++
+		case task.InProgress.String():
+			s.InProgress++
+		case task.Done.String():
+			s.Done++
+		}
+		if t.Status != task.Done.String() && (s.OldestOpen.IsZero() || t.Created.Before(s.OldestOpen)) {
+			s.OldestOpen = t.Created
+		}
+		if t.Updated.After(s.LastActivity) {
+			s.LastActivity = t.Updated
+		}
+	}
+
+	summaries := make([]ProjectSummary, 0, len(byProject))
+	for _, s := range byProject {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Project < summaries[j].Project })
+	return summaries
+}
+
+// NextPosition returns the position for a task ranked after all of tasks. Exported
+func NextPosition(tasks []task.Task) float64 {
+	var max float64
+	for _, t := range tasks {
+		if t.Position > max {
+			max = t.Position
+		}
+	}
+	return max + positionGap
+}
+
+// Placement says where Rerank moves a task. Exported
+type Placement int
+
+// Placements accepted by Rerank.
+const (
+	PlaceTop Placement = iota
+	PlaceBottom
+	PlaceBefore
+	PlaceAfter
+)
+
+// Rerank changes the Position of task id in tasks, in place, as MoveToTop,
+// MoveToBottom, MoveBefore or MoveAfter would; otherID is only used for
+// PlaceBefore and PlaceAfter. Like TaskDB, it renumbers every task when the
+// gap next to otherID is too small, so callers must persist all tasks whose
+// position changed. Exported
+func Rerank(tasks []task.Task, id, otherID uint, place Placement) error {
+	idx, anchor := -1, -1
+	for i, t := range tasks {
+		switch t.ID {
+		case id:
+			idx = i
+		case otherID:
+			anchor = i
+		}
+	}
+	if idx < 0 {
+		return Errorf(ErrNotFound, "task with ID %d not found", id)
+	}
+
+	switch place {
+	case PlaceTop, PlaceBottom:
+		var bound float64
+		first := true
+		for i, t := range tasks {
+			if i == idx {
+				continue
+			}
+			if first || (place == PlaceTop && t.Position < bound) || (place == PlaceBottom && t.Position > bound) {
+				bound, first = t.Position, false
+			}
+		}
+		if place == PlaceTop {
+			tasks[idx].Position = bound - positionGap
+		} else {
+			tasks[idx].Position = bound + positionGap
+		}
+		return nil
+	}
+
+	if id == otherID {
+		return Errorf(ErrInvalidInput, "cannot move task %d relative to itself", id)
+	}
+	if anchor < 0 {
+		return Errorf(ErrNotFound, "move failed for id %d: task with ID %d not found", id, otherID)
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		pos := tasks[anchor].Position
+		found := false
+		var neighbour float64
+		for i, t := range tasks {
+			if i == idx {
+				continue
+			}
+			if place == PlaceBefore && t.Position < pos && (!found || t.Position > neighbour) {
+				neighbour, found = t.Position, true
+			}
+			if place == PlaceAfter && t.Position > pos && (!found || t.Position < neighbour) {
+				neighbour, found = t.Position, true
+			}
+		}
+		switch {
+		case !found && place == PlaceBefore:
+			tasks[idx].Position = pos - positionGap
+			return nil
+		case !found:
+			tasks[idx].Position = pos + positionGap
+			return nil
+		}
+		gap := pos - neighbour
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap >= minPositionGap {
+			tasks[idx].Position = (pos + neighbour) / 2
+			return nil
+		}
+		renumberSlice(tasks)
+	}
+	return fmt.Errorf("move failed for id %d: could not find a free position next to task %d", id, otherID)
+}
+
+// renumberSlice spaces tasks positionGap apart, keeping their order. (Unexported)
+func renumberSlice(tasks []task.Task) {
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := tasks[order[i]], tasks[order[j]]
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	})
+	for rank, i := range order {
+		tasks[i].Position = float64(rank+1) * positionGap
+	}
+}
+
+// PrepareInsert returns the task Insert would store for t, before it is
+// given an ID and position: todo unless t has a status, created and updated
+// now, completed now if it is done, and with normalised tags and due date.
+// Exported
+func PrepareInsert(t task.Task, now time.Time) (task.Task, error) {
+	nt := task.Task{Name: t.Name, Project: t.Project, Status: t.Status, Tags: task.NormalizeTags(t.Tags), Due: task.Day(t.Due), Created: now, Updated: now}
+	if nt.Status == "" {
+		nt.Status = task.Todo.String()
+	}
+	if err := ValidateTask(nt); err != nil {
+		return task.Task{}, err
+	}
+	if nt.Status == task.Done.String() {
+		nt.Completed = now
+	}
+	return nt, nil
+}
+
+// ApplyUpdate changes t as Update would, returning whether anything was set.
+// The caller stores the result. Exported
+func ApplyUpdate(t *task.Task, ch Changes, now time.Time) bool {
+	if ch.IsZero() {
+		return false
+	}
+	if ch.Name != nil {
+		t.Name = *ch.Name
+	}
+	if ch.Project != nil {
+		t.Project = *ch.Project
+	}
+	if ch.Status != nil {
+		if *ch.Status != t.Status {
+			if *ch.Status == task.Done.String() {
+				t.Completed = now
+			} else {
+				t.Completed = time.Time{}
+			}
+		}
+		t.Status = *ch.Status
+	}
+	if ch.Tags != nil {
+		t.Tags = task.NormalizeTags(*ch.Tags)
+	}
+	if ch.Due != nil {
+		t.Due = task.Day(*ch.Due)
+	}
+	t.Updated = now
+	return true
+}
+
+// ValidateTask checks the constraints the tasks table enforces: a non-empty
+// name, a known status and tags without separators. Errors match
+// ErrInvalidInput. Exported
+func ValidateTask(t task.Task) error {
+	return validateUpdate(Changes{Name: &t.Name, Status: &t.Status, Tags: &t.Tags})
+}
+
+// validateUpdate checks the fields an update sets, skipping nil ones. (Unexported)
+func validateUpdate(ch Changes) error {
+	if ch.Name != nil && *ch.Name == "" {
+		return Errorf(ErrInvalidInput, "task name cannot be empty")
+	}
+	if ch.Status != nil {
+		if _, err := task.ParseStatus(*ch.Status); err != nil {
+			return Errorf(ErrInvalidInput, "%w", err)
+		}
+	}
+	if ch.Tags != nil {
+		for _, tag := range task.NormalizeTags(*ch.Tags) {
+			if err := task.ValidateTag(tag); err != nil {
+				return Errorf(ErrInvalidInput, "%w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// PrepareImport fills in the defaults ImportTasks applies to an imported
+// task: a trimmed name, todo status, creation time of now, and timestamps
+// consistent with the status. index is used in error messages. Exported
+func PrepareImport(t task.Task, index int, now time.Time) (task.Task, error) {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return t, Errorf(ErrInvalidInput, "task %d has no name", index+1)
+	}
+	if t.Status == "" {
+		t.Status = task.Todo.String()
+	}
+	if _, err := task.ParseStatus(t.Status); err != nil {
+		return t, Errorf(ErrInvalidInput, "task %d (%q): %w", index+1, t.Name, err)
+	}
+	t.Tags = task.NormalizeTags(t.Tags)
+	t.Due = task.Day(t.Due)
+	for _, tag := range t.Tags {
+		if err := task.ValidateTag(tag); err != nil {
+			return t, Errorf(ErrInvalidInput, "task %d (%q): %w", index+1, t.Name, err)
+		}
+	}
+	if t.Created.IsZero() {
+		t.Created = now
+	}
+	if t.Status != task.Done.String() {
+		t.Completed = time.Time{}
+	} else if t.Completed.IsZero() {
+		t.Completed = now
+	}
+	if t.Updated.IsZero() {
+		t.Updated = t.Created
+		if t.Completed.After(t.Updated) {
+			t.Updated = t.Completed
+		}
+	}
+	return t, nil
+}
+
+// DuplicateKey identifies tasks that ImportTasks treats as the same: equal
+// projects and names that match ignoring case and surrounding space. Exported
+func DuplicateKey(name, project string) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "\x00" + project
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// sortColumns maps user-facing sort field names to SQL expressions. Status is
+// ordered by workflow position rather than alphabetically.
+var sortColumns = map[string]string{
+	"id":      "id",
+	"name":    "name COLLATE NOCASE",
+	"project": "COALESCE(project, '') COLLATE NOCASE",
+	"status": fmt.Sprintf("CASE status WHEN '%s' THEN %d WHEN '%s' THEN %d WHEN '%s' THEN %d END",
+		task.Todo, task.Todo, task.InProgress, task.InProgress, task.Done, task.Done),
+	"created":  "julianday(created)",
+	"position": "position",
+	"updated":  "julianday(COALESCE(updated, created))",
+	"due":      "COALESCE(due, '9999-12-31')", // Tasks without a due date last
+}
+
+// SortFields returns the field names accepted by ParseSortKeys. Exported
+func SortFields() []string {
+	return []string{"id", "name", "project", "status", "position", "created", "updated", "due"}
+}
+
+// SortKey orders task listings by a single field. Exported
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSortKeys parses a comma-separated list like "status,created:desc". Exported
+func ParseSortKeys(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, dir, hasDir := strings.Cut(part, ":")
+		key := SortKey{Field: strings.ToLower(field)}
+		if _, ok := sortColumns[key.Field]; !ok {
+			return nil, Errorf(ErrInvalidInput, "unknown sort field %q (valid: %s)", field, strings.Join(SortFields(), ", "))
+		}
+		if hasDir {
+			switch strings.ToLower(dir) {
+			case "asc":
+			case "desc":
+				key.Desc = true
+			default:
+				return nil, Errorf(ErrInvalidInput, "invalid sort direction %q for field %q (use asc or desc)", dir, field)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ListOptions controls which tasks ListTasks returns and in what order. Exported
+type ListOptions struct {
+	Sort      []SortKey // Defaults to manual rank within status when empty
+	Project   string    // Only tasks in this project; empty matches all
+	Tag       string    // Only tasks with this tag; empty matches all
+	Statuses  []string  // Only tasks in one of these statuses; empty matches all
+	HideDone  bool      // Exclude tasks that are done
+	DoneSince time.Time // Exclude done tasks completed before this; zero keeps all
+}
+
+// where builds the WHERE clause and its arguments for the options. (Unexported)
+func (o ListOptions) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if o.Project != "" {
+		conds = append(conds, "project = ?")
+		args = append(args, o.Project)
+	}
+	if o.Tag != "" {
+		// Tags are stored space-separated, so pad both sides to match whole tags.
+		conds = append(conds, "instr(' ' || tags || ' ', ?) > 0")
+		args = append(args, " "+o.Tag+" ")
+	}
+	if len(o.Statuses) > 0 {
+		conds = append(conds, "status IN (?"+strings.Repeat(", ?", len(o.Statuses)-1)+")")
+		for _, s := range o.Statuses {
+			args = append(args, s)
+		}
+	}
+	if o.HideDone {
+		conds = append(conds, "status != ?")
+		args = append(args, task.Done.String())
+	} else if !o.DoneSince.IsZero() {
+		conds = append(conds, "(status != ? OR julianday(completed) >= julianday(?))")
+		args = append(args, task.Done.String(), o.DoneSince.UTC())
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// orderBy builds the ORDER BY clause for the options. Status, manual rank
+// and finally ID are appended as tie-breakers so the default order is rank
+// within status and output is always stable. (Unexported)
+func (o ListOptions) orderBy() (string, error) {
+	keys := make([]SortKey, 0, len(o.Sort)+2)
+	keys = append(keys, o.Sort...)
+	keys = append(keys, SortKey{Field: "status"}, SortKey{Field: "position"})
+	terms := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		expr, ok := sortColumns[k.Field]
+		if !ok {
+			return "", fmt.Errorf("unknown sort field %q", k.Field)
+		}
+		if k.Desc {
+			expr += " DESC"
+		} else {
+			expr += " ASC"
+		}
+		terms = append(terms, expr)
+	}
+	terms = append(terms, "id ASC")
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// ListTasks retrieves tasks according to the given options.
+func (tdb *TaskDB) ListTasks(opts ListOptions) ([]task.Task, error) {
+	return tdb.ListTasksContext(context.Background(), opts)
+}
+
+// ListTasksContext is like ListTasks but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) ListTasksContext(ctx context.Context, opts ListOptions) ([]task.Task, error) {
+	orderBy, err := opts.orderBy()
+	if err != nil {
+		return nil, err
+	}
+	where, args := opts.where()
+	tasks := []task.Task{}
+	rows, err := tdb.db.QueryContext(ctx, "SELECT "+taskColumns+" FROM tasks "+where+" "+orderBy, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query tasks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scanning task row: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task rows: %w", err)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// migrations holds the schema changes applied on top of the original
+// 'tasks' table, in order. The number of applied migrations is recorded in
+// SQLite's user_version pragma, so entries must never be reordered or
+// removed; append new ones to the end.
+var migrations = []string{
+	// 1: track when a task was last modified.
+	`ALTER TABLE tasks ADD COLUMN "updated" DATETIME;
+	UPDATE tasks SET updated = created;`,
+	// 2: normalise timestamps written with a local offset (or by
+	// CURRENT_TIMESTAMP without one) to UTC in a single format.
+	`UPDATE tasks SET
+		created = strftime('%Y-%m-%d %H:%M:%f+00:00', created),
+		updated = strftime('%Y-%m-%d %H:%M:%f+00:00', COALESCE(updated, created));`,
+	// 3: record when a task was finished. For tasks already done, the last
+	// modification is the best available estimate.
+	`ALTER TABLE tasks ADD COLUMN "completed" DATETIME;
+	UPDATE tasks SET completed = updated WHERE status = 'done';`,
+	// 4: manual ordering. Existing tasks keep their creation order.
+	`ALTER TABLE tasks ADD COLUMN "position" REAL NOT NULL DEFAULT 0;
+	UPDATE tasks SET position = id * 1024.0;`,
+	// 5: webhook deliveries waiting to be sent. A NULL next_attempt marks a
+	// delivery that was given up on.
+	`CREATE TABLE "webhook_outbox" (
+		"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+		"url" TEXT NOT NULL,
+		"event" TEXT NOT NULL,
+		"payload" BLOB NOT NULL,
+		"attempts" INTEGER NOT NULL DEFAULT 0,
+		"next_attempt" DATETIME,
+		"last_error" TEXT NOT NULL DEFAULT '',
+		"created" DATETIME NOT NULL
+	);
+	CREATE INDEX "webhook_outbox_due" ON "webhook_outbox"("next_attempt");`,
+	// 6: tags, stored sorted and separated by single spaces.
+	`ALTER TABLE tasks ADD COLUMN "tags" TEXT NOT NULL DEFAULT '';`,
+	// 7: due dates, as YYYY-MM-DD text so they sort and compare as dates.
+	`ALTER TABLE tasks ADD COLUMN "due" TEXT;`,
+	// 8: which configured webhook a delivery is for, by its position in the
+	// config, since several webhooks may share a URL. -1 for deliveries
+	// queued before, which are matched by URL alone.
+	`ALTER TABLE webhook_outbox ADD COLUMN "hook" INTEGER NOT NULL DEFAULT -1;`,
+}
+
+// SchemaVersion returns the number of migrations applied to the database. Exported
+func (tdb *TaskDB) SchemaVersion() (int, error) {
+	return tdb.SchemaVersionContext(context.Background())
+}
+
+// SchemaVersionContext is like SchemaVersion but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) SchemaVersionContext(ctx context.Context) (int, error) {
+	var version int
+	if err := tdb.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate applies any pending migrations, each in its own transaction. (Unexported)
+func (tdb *TaskDB) migrate(ctx context.Context) error {
+	version, err := tdb.SchemaVersionContext(ctx)
+	if err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := tdb.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record schema version %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
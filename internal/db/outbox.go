@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// OutboxEntry is a webhook delivery queued for a change. Exported
+type OutboxEntry struct {
+	Hook    int // Index of the webhook in the config
+	URL     string
+	Event   task.ChangeType
+	Payload []byte
+}
+
+// Router returns the deliveries a change to a task calls for. Exported
+type Router func(c task.Change) ([]OutboxEntry, error)
+
+// Delivery is a queued webhook delivery. Exported
+type Delivery struct {
+	ID          int64
+	Hook        int // Index of the webhook in the config when queued; -1 if unknown
+	URL         string
+	Event       task.ChangeType
+	Payload     []byte
+	Attempts    int       // Failed attempts so far
+	NextAttempt time.Time // Zero once the delivery was given up on
+	LastError   string
+	Created     time.Time
+}
+
+// SetRouter makes later changes to tasks queue the deliveries router returns
+// in the webhook outbox, in the same transaction as the change, so they are
+// kept exactly when the change is. A nil router queues nothing. Doctor
+// repairs and the renumbering of positions are not reported. Exported
+func (tdb *TaskDB) SetRouter(router Router) {
+	tdb.router = router
+}
+
+// record queues the deliveries for changes made in tx. (Unexported)
+func (tdb *TaskDB) record(ctx context.Context, tx *sql.Tx, changes ...task.Change) error {
+	if tdb.router == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	for _, c := range changes {
+		entries, err := tdb.router(c)
+		if err != nil {
+			return fmt.Errorf("failed to prepare webhook for task %d: %w", c.Task.ID, err)
+		}
+		for _, e := range entries {
+			_, err := tx.ExecContext(ctx, "INSERT INTO webhook_outbox(hook, url, event, payload, next_attempt, created) VALUES(?, ?, ?, ?, ?, ?)",
+				e.Hook, e.URL, string(e.Event), e.Payload, now, now)
+			if err != nil {
+				return fmt.Errorf("failed to queue webhook for task %d: %w", c.Task.ID, classify(err))
+			}
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, committing if it succeeds. (Unexported)
+func (tdb *TaskDB) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := tdb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classify(err))
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return classify(tx.Commit())
+}
+
+// ClaimDeliveries returns up to limit deliveries due at now, and hides them
+// from other callers until now+lease, so that concurrent taskly processes
+// do not send the same delivery twice. A claimed delivery that is neither
+// marked delivered nor failed becomes due again once the lease ends. Exported
+func (tdb *TaskDB) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	return tdb.ClaimDeliveriesContext(context.Background(), now, lease, limit)
+}
+
+// ClaimDeliveriesContext is like ClaimDeliveries but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) ClaimDeliveriesContext(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	return tdb.claimDeliveries(ctx, "next_attempt <= ?", now, lease, limit)
+}
+
+// ClaimNewDeliveries is like ClaimDeliveries but only claims deliveries
+// that have not been attempted yet, leaving retries of failed ones for
+// later. Exported
+func (tdb *TaskDB) ClaimNewDeliveries(now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	return tdb.ClaimNewDeliveriesContext(context.Background(), now, lease, limit)
+}
+
+// ClaimNewDeliveriesContext is like ClaimNewDeliveries but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) ClaimNewDeliveriesContext(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	return tdb.claimDeliveries(ctx, "next_attempt <= ? AND attempts = 0", now, lease, limit)
+}
+
+// claimDeliveries claims up to limit deliveries matching where, a condition
+// on a single parameter, now. (Unexported)
+func (tdb *TaskDB) claimDeliveries(ctx context.Context, where string, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	// Look before taking the write lock, as there is usually nothing due.
+	var found int
+	err := tdb.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM (SELECT 1 FROM webhook_outbox WHERE "+where+" LIMIT 1)", now.UTC()).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query webhook deliveries: %w", classify(err))
+	}
+	if found == 0 {
+		return nil, nil
+	}
+
+	var due []Delivery
+	err = tdb.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		due, err = queryDeliveries(ctx, tx, "WHERE "+where+" ORDER BY next_attempt, id LIMIT ?", now.UTC(), limit)
+		if err != nil {
+			return err
+		}
+		for _, d := range due {
+			if _, err := tx.ExecContext(ctx, "UPDATE webhook_outbox SET next_attempt = ? WHERE id = ?", now.Add(lease).UTC(), d.ID); err != nil {
+				return fmt.Errorf("failed to claim delivery %d: %w", d.ID, classify(err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// Deliveries returns every queued delivery, including those given up on,
+// oldest first. Exported
+func (tdb *TaskDB) Deliveries() ([]Delivery, error) {
+	return tdb.DeliveriesContext(context.Background())
+}
+
+// DeliveriesContext is like Deliveries but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) DeliveriesContext(ctx context.Context) ([]Delivery, error) {
+	return queryDeliveries(ctx, tdb.db, "ORDER BY id")
+}
+
+// MarkDelivered removes a delivery that was sent. Exported
+func (tdb *TaskDB) MarkDelivered(id int64) error {
+	return tdb.MarkDeliveredContext(context.Background(), id)
+}
+
+// MarkDeliveredContext is like MarkDelivered but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) MarkDeliveredContext(ctx context.Context, id int64) error {
+	if _, err := tdb.db.ExecContext(ctx, "DELETE FROM webhook_outbox WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to remove delivery %d: %w", id, classify(err))
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt at a delivery and when to try again;
+// a zero retryAt gives up on it. Exported
+func (tdb *TaskDB) MarkFailed(id int64, reason string, retryAt time.Time) error {
+	return tdb.MarkFailedContext(context.Background(), id, reason, retryAt)
+}
+
+// MarkFailedContext is like MarkFailed but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) MarkFailedContext(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	_, err := tdb.db.ExecContext(ctx, "UPDATE webhook_outbox SET attempts = attempts + 1, last_error = ?, next_attempt = ? WHERE id = ?",
+		reason, nullTime(retryAt), id)
+	if err != nil {
+		return fmt.Errorf("failed to record failed delivery %d: %w", id, classify(err))
+	}
+	return nil
+}
+
+// RetryFailed makes the deliveries that were given up on due again, with a
+// fresh attempt count, and returns how many there were. Exported
+func (tdb *TaskDB) RetryFailed() (int, error) {
+	return tdb.RetryFailedContext(context.Background())
+}
+
+// RetryFailedContext is like RetryFailed but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) RetryFailedContext(ctx context.Context) (int, error) {
+	res, err := tdb.db.ExecContext(ctx, "UPDATE webhook_outbox SET attempts = 0, next_attempt = ? WHERE next_attempt IS NULL", time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry deliveries: %w", classify(err))
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx. (Unexported)
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// queryDeliveries reads the outbox rows selected by clause. (Unexported)
+func queryDeliveries(ctx context.Context, q querier, clause string, args ...interface{}) ([]Delivery, error) {
+	rows, err := q.QueryContext(ctx, "SELECT id, hook, url, event, payload, attempts, next_attempt, last_error, created FROM webhook_outbox "+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query webhook deliveries: %w", classify(err))
+	}
+	defer rows.Close()
+	var deliveries []Delivery
+	for rows.Next() {
+		var d Delivery
+		var event string
+		var next sql.NullTime
+		if err := rows.Scan(&d.ID, &d.Hook, &d.URL, &event, &d.Payload, &d.Attempts, &next, &d.LastError, &d.Created); err != nil {
+			return nil, fmt.Errorf("failed scanning delivery row: %w", err)
+		}
+		d.Event = task.ChangeType(event)
+		if next.Valid {
+			d.NextAttempt = next.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delivery rows: %w", err)
+	}
+	return deliveries, nil
+}
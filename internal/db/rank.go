@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// positionGap is the spacing between neighbouring tasks when positions are
+// assigned from scratch, leaving room to insert between them by halving.
+const positionGap = 1024.0
+
+// minPositionGap is the smallest gap allowed between neighbours before all
+// positions are renumbered.
+const minPositionGap = 1e-6
+
+// MoveToTop ranks a task before every other task.
+func (tdb *TaskDB) MoveToTop(id uint) error {
+	return tdb.MoveToTopContext(context.Background(), id)
+}
+
+// MoveToTopContext is like MoveToTop but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) MoveToTopContext(ctx context.Context, id uint) error {
+	return tdb.reposition(ctx, id, func(tx *sql.Tx) (float64, error) {
+		var min sql.NullFloat64
+		if err := tx.QueryRowContext(ctx, "SELECT MIN(position) FROM tasks WHERE id != ?", id).Scan(&min); err != nil {
+			return 0, err
+		}
+		return min.Float64 - positionGap, nil
+	})
+}
+
+// MoveToBottom ranks a task after every other task.
+func (tdb *TaskDB) MoveToBottom(id uint) error {
+	return tdb.MoveToBottomContext(context.Background(), id)
+}
+
+// MoveToBottomContext is like MoveToBottom but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) MoveToBottomContext(ctx context.Context, id uint) error {
+	return tdb.reposition(ctx, id, func(tx *sql.Tx) (float64, error) {
+		var max sql.NullFloat64
+		if err := tx.QueryRowContext(ctx, "SELECT MAX(position) FROM tasks WHERE id != ?", id).Scan(&max); err != nil {
+			return 0, err
+		}
+		return max.Float64 + positionGap, nil
+	})
+}
+
+// MoveBefore ranks a task immediately before another one.
+func (tdb *TaskDB) MoveBefore(id, otherID uint) error {
+	return tdb.MoveBeforeContext(context.Background(), id, otherID)
+}
+
+// MoveBeforeContext is like MoveBefore but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) MoveBeforeContext(ctx context.Context, id, otherID uint) error {
+	return tdb.moveNextTo(ctx, id, otherID, true)
+}
+
+// MoveAfter ranks a task immediately after another one.
+func (tdb *TaskDB) MoveAfter(id, otherID uint) error {
+	return tdb.MoveAfterContext(context.Background(), id, otherID)
+}
+
+// MoveAfterContext is like MoveAfter but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) MoveAfterContext(ctx context.Context, id, otherID uint) error {
+	return tdb.moveNextTo(ctx, id, otherID, false)
+}
+
+// moveNextTo places a task halfway between otherID and its neighbour on the
+// requested side, renumbering all tasks first if the gap is too small. (Unexported)
+func (tdb *TaskDB) moveNextTo(ctx context.Context, id, otherID uint, before bool) error {
+	if id == otherID {
+		return Errorf(ErrInvalidInput, "cannot move task %d relative to itself", id)
+	}
+	return tdb.reposition(ctx, id, func(tx *sql.Tx) (float64, error) {
+		for attempt := 0; attempt < 2; attempt++ {
+			var anchor float64
+			err := tx.QueryRowContext(ctx, "SELECT position FROM tasks WHERE id = ?", otherID).Scan(&anchor)
+			if err == sql.ErrNoRows {
+				return 0, Errorf(ErrNotFound, "task with ID %d not found", otherID)
+			} else if err != nil {
+				return 0, err
+			}
+
+			var neighbour sql.NullFloat64
+			query := "SELECT MAX(position) FROM tasks WHERE position < ? AND id != ?"
+			if !before {
+				query = "SELECT MIN(position) FROM tasks WHERE position > ? AND id != ?"
+			}
+			if err := tx.QueryRowContext(ctx, query, anchor, id).Scan(&neighbour); err != nil {
+				return 0, err
+			}
+
+			switch {
+			case !neighbour.Valid && before:
+				return anchor - positionGap, nil
+			case !neighbour.Valid:
+				return anchor + positionGap, nil
+			}
+			gap := anchor - neighbour.Float64
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap >= minPositionGap {
+				return (anchor + neighbour.Float64) / 2, nil
+			}
+			if err := renumberPositions(ctx, tx); err != nil {
+				return 0, err
+			}
+		}
+		return 0, fmt.Errorf("could not find a free position next to task %d", otherID)
+	})
+}
+
+// reposition runs pick inside a transaction and stores the position it
+// returns for the task. (Unexported)
+func (tdb *TaskDB) reposition(ctx context.Context, id uint, pick func(tx *sql.Tx) (float64, error)) error {
+	tx, err := tdb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin move: %w", classify(err))
+	}
+	defer tx.Rollback()
+
+	before, err := getTask(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	position, err := pick(tx)
+	if err != nil {
+		return fmt.Errorf("move failed for id %d: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE tasks SET position = ?, updated = ? WHERE id = ?", position, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("move failed for id %d: %w", id, classify(err))
+	}
+	// Renumbering may have moved the task too, so compare with what it was
+	// before the whole move.
+	after, err := getTask(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := tdb.record(ctx, tx, task.Change{Type: task.Updated, Task: after, Previous: before}); err != nil {
+		return err
+	}
+	return classify(tx.Commit())
+}
+
+// renumberPositions spaces all tasks positionGap apart, keeping their order. (Unexported)
+func renumberPositions(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM tasks ORDER BY position ASC, id ASC")
+	if err != nil {
+		return fmt.Errorf("failed reading task order: %w", err)
+	}
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE tasks SET position = ? WHERE id = ?", float64(i+1)*positionGap, id); err != nil {
+			return fmt.Errorf("failed renumbering task %d: %w", id, classify(err))
+		}
+	}
+	return nil
+}
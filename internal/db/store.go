@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Store is the task storage used by taskly's commands and interfaces. TaskDB
+// is the SQLite implementation; other backends use the helpers in
+// inmemory.go to behave identically. Every operation takes a context so a
+// stuck or slow backend can be cancelled. Exported
+type Store interface {
+	InsertContext(ctx context.Context, name, project string) (task.Task, error)
+	InsertTaskContext(ctx context.Context, t task.Task) (task.Task, error)
+	RestoreContext(ctx context.Context, t task.Task) error
+	UpdateContext(ctx context.Context, id uint, ch Changes) (task.Task, error)
+	DeleteContext(ctx context.Context, id uint) error
+	// DeleteIfUpdatedContext is like DeleteContext but fails with
+	// ErrConflict unless the task was last updated at updated.
+	DeleteIfUpdatedContext(ctx context.Context, id uint, updated time.Time) error
+
+	GetTaskContext(ctx context.Context, id uint) (task.Task, error)
+	GetTasksContext(ctx context.Context) ([]task.Task, error)
+	GetTasksByStatusContext(ctx context.Context, status string) ([]task.Task, error)
+	ListTasksContext(ctx context.Context, opts ListOptions) ([]task.Task, error)
+	CountTasksByStatusContext(ctx context.Context) (map[string]int, error)
+	GetProjectSummariesContext(ctx context.Context) ([]ProjectSummary, error)
+
+	MoveToTopContext(ctx context.Context, id uint) error
+	MoveToBottomContext(ctx context.Context, id uint) error
+	MoveBeforeContext(ctx context.Context, id, otherID uint) error
+	MoveAfterContext(ctx context.Context, id, otherID uint) error
+
+	ImportTasksContext(ctx context.Context, tasks []task.Task, opts ImportOptions) (ImportResult, error)
+
+	// NewWatcher returns a watcher reporting changes made by other processes.
+	NewWatcher() (ChangeWatcher, error)
+	Close() error
+}
+
+// Changes lists the fields Update sets on a task; nil fields are left
+// unchanged. Exported
+type Changes struct {
+	Name    *string
+	Project *string
+	Status  *string
+	Tags    *[]string  // Replaces all of the task's tags; an empty list removes them
+	Due     *time.Time // The zero time removes the due date
+
+	// IfUpdated, if set, makes the update fail with ErrConflict unless the
+	// task was last updated at this time, so a change based on a stale copy
+	// of the task cannot overwrite someone else's.
+	IfUpdated time.Time
+}
+
+// IsZero reports whether the changes leave every field alone. Exported
+func (ch Changes) IsZero() bool {
+	ch.IfUpdated = time.Time{}
+	return ch == Changes{}
+}
+
+// CheckUnchanged returns an ErrConflict error if ch is conditional on an
+// updated time t no longer has. Exported
+func CheckUnchanged(t task.Task, ch Changes) error {
+	if !ch.IfUpdated.IsZero() && !t.Updated.Equal(ch.IfUpdated) {
+		return Errorf(ErrConflict, "task %d was changed since %s", t.ID, ch.IfUpdated.Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+// ChangeWatcher reports whether a store was modified since the last call. Exported
+type ChangeWatcher interface {
+	Changed() (bool, error)
+	Close() error
+}
+
+// TaskDB must satisfy Store.
+var _ Store = (*TaskDB)(nil)
@@ -0,0 +1,643 @@
+// Package storetest is a conformance suite for db.Store implementations, so
+// every backend behaves like the SQLite database.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Opener returns a new, empty store. It is called once per test, which
+// should register any cleanup with t.Cleanup.
+type Opener func(t *testing.T) db.Store
+
+// Run runs the conformance suite against stores returned by open.
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s db.Store)
+	}{
+		{"InsertAndGet", testInsertAndGet},
+		{"InsertValidation", testInsertValidation},
+		{"Update", testUpdate},
+		{"UpdateCompletion", testUpdateCompletion},
+		{"Tags", testTags},
+		{"Due", testDue},
+		{"Delete", testDelete},
+		{"Restore", testRestore},
+		{"DefaultOrder", testDefaultOrder},
+		{"ListFilters", testListFilters},
+		{"ListSort", testListSort},
+		{"GetTasksByStatus", testGetTasksByStatus},
+		{"Counts", testCounts},
+		{"ProjectSummaries", testProjectSummaries},
+		{"Move", testMove},
+		{"MoveErrors", testMoveErrors},
+		{"Import", testImport},
+		{"ImportDryRun", testImportDryRun},
+		{"ImportIsAtomic", testImportIsAtomic},
+		{"Watcher", testWatcher},
+		{"CancelledContext", testCancelledContext},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s := open(t)
+			tc.fn(t, s)
+		})
+	}
+}
+
+// mustInsert inserts a task or fails the test.
+func mustInsert(t *testing.T, s db.Store, name, project, status string) task.Task {
+	t.Helper()
+	ctx := context.Background()
+	tk, err := s.InsertTaskContext(ctx, task.Task{Name: name, Project: project, Status: status})
+	if err != nil {
+		t.Fatalf("InsertTask(%q, %q, %q): %v", name, project, status, err)
+	}
+	return tk
+}
+
+// mustList lists tasks or fails the test.
+func mustList(t *testing.T, s db.Store, opts db.ListOptions) []task.Task {
+	t.Helper()
+	ctx := context.Background()
+	tasks, err := s.ListTasksContext(ctx, opts)
+	if err != nil {
+		t.Fatalf("ListTasks(%+v): %v", opts, err)
+	}
+	return tasks
+}
+
+// names returns the names of tasks, in order.
+func names(tasks []task.Task) []string {
+	out := make([]string, len(tasks))
+	for i, t := range tasks {
+		out[i] = t.Name
+	}
+	return out
+}
+
+// checkNames fails the test unless tasks have exactly the wanted names, in order.
+func checkNames(t *testing.T, tasks []task.Task, want ...string) {
+	t.Helper()
+	got := names(tasks)
+	if len(got) != len(want) {
+		t.Fatalf("got tasks %q, want %q", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got tasks %q, want %q", got, want)
+		}
+	}
+}
+
+// ptr returns a pointer to s, for Update arguments.
+func ptr(s string) *string { return &s }
+
+func testInsertAndGet(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	before := time.Now().Add(-time.Second)
+	inserted, err := s.InsertContext(ctx, "Write tests", "taskly")
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if inserted.ID == 0 {
+		t.Fatal("Insert returned a task without an ID")
+	}
+	if inserted.Status != task.Todo.String() {
+		t.Errorf("new task status = %q, want %q", inserted.Status, task.Todo)
+	}
+	if inserted.Created.Before(before) || !inserted.Updated.Equal(inserted.Created) {
+		t.Errorf("new task timestamps created=%v updated=%v, want both now", inserted.Created, inserted.Updated)
+	}
+	if !inserted.Completed.IsZero() {
+		t.Errorf("new todo task has completion time %v", inserted.Completed)
+	}
+
+	got, err := s.GetTaskContext(ctx, inserted.ID)
+	if err != nil {
+		t.Fatalf("GetTask(%d): %v", inserted.ID, err)
+	}
+	if got.Name != "Write tests" || got.Project != "taskly" || got.Status != inserted.Status ||
+		!got.Created.Equal(inserted.Created) || got.Position != inserted.Position {
+		t.Errorf("GetTask = %+v, want %+v", got, inserted)
+	}
+
+	second := mustInsert(t, s, "Second", "", task.Done.String())
+	if second.ID == inserted.ID {
+		t.Errorf("two tasks share ID %d", second.ID)
+	}
+	if second.Completed.IsZero() {
+		t.Error("task inserted as done has no completion time")
+	}
+	if second.Position <= inserted.Position {
+		t.Errorf("later task position %v is not after %v", second.Position, inserted.Position)
+	}
+
+	if _, err := s.GetTaskContext(ctx, second.ID+100); err == nil {
+		t.Error("GetTask of a missing ID succeeded")
+	}
+}
+
+func testInsertValidation(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	if _, err := s.InsertContext(ctx, "", "p"); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("Insert with an empty name: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.InsertTaskContext(ctx, task.Task{Name: "x", Status: "doing"}); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("InsertTask with an unknown status: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.InsertTaskContext(ctx, task.Task{Name: "x", Tags: []string{"two words"}}); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("InsertTask with a tag containing a space: err = %v, want ErrInvalidInput", err)
+	}
+	if tasks := mustList(t, s, db.ListOptions{}); len(tasks) != 0 {
+		t.Errorf("failed inserts left tasks behind: %q", names(tasks))
+	}
+}
+
+func testUpdate(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	orig := mustInsert(t, s, "Old name", "old", task.Todo.String())
+	time.Sleep(2 * time.Millisecond)
+
+	updated, err := s.UpdateContext(ctx, orig.ID, db.Changes{Name: ptr("New name"), Project: ptr("new")})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "New name" || updated.Project != "new" || updated.Status != orig.Status {
+		t.Errorf("Update returned %+v", updated)
+	}
+	if !updated.Updated.After(orig.Updated) {
+		t.Errorf("Update did not advance updated time: %v -> %v", orig.Updated, updated.Updated)
+	}
+	got, err := s.GetTaskContext(ctx, orig.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Name != "New name" || got.Project != "new" {
+		t.Errorf("stored task after Update = %+v", got)
+	}
+
+	unchanged, err := s.UpdateContext(ctx, orig.ID, db.Changes{})
+	if err != nil {
+		t.Fatalf("Update without changes: %v", err)
+	}
+	if !unchanged.Updated.Equal(got.Updated) {
+		t.Errorf("Update without changes modified updated time: %v -> %v", got.Updated, unchanged.Updated)
+	}
+
+	if _, err := s.UpdateContext(ctx, orig.ID, db.Changes{Name: ptr("Stale"), IfUpdated: orig.Updated}); !errors.Is(err, db.ErrConflict) {
+		t.Errorf("Update based on a stale copy: err = %v, want ErrConflict", err)
+	}
+	if current, err := s.UpdateContext(ctx, orig.ID, db.Changes{Project: ptr("newer"), IfUpdated: got.Updated}); err != nil || current.Project != "newer" {
+		t.Errorf("Update based on the current copy = %+v, %v", current, err)
+	}
+
+	if _, err := s.UpdateContext(ctx, orig.ID+100, db.Changes{Name: ptr("x")}); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("Update of a missing ID: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.UpdateContext(ctx, orig.ID, db.Changes{Name: ptr("")}); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("Update to an empty name: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.UpdateContext(ctx, orig.ID, db.Changes{Status: ptr("doing")}); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("Update to an unknown status: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func testUpdateCompletion(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	tk := mustInsert(t, s, "Finish me", "", task.Todo.String())
+
+	done, err := s.UpdateContext(ctx, tk.ID, db.Changes{Status: ptr(task.Done.String())})
+	if err != nil {
+		t.Fatalf("Update to done: %v", err)
+	}
+	if done.Completed.IsZero() {
+		t.Fatal("task marked done has no completion time")
+	}
+
+	// Renaming a done task keeps its completion time.
+	renamed, err := s.UpdateContext(ctx, tk.ID, db.Changes{Name: ptr("Finished"), Status: ptr(task.Done.String())})
+	if err != nil {
+		t.Fatalf("Update of done task: %v", err)
+	}
+	if !renamed.Completed.Equal(done.Completed) {
+		t.Errorf("completion time changed from %v to %v", done.Completed, renamed.Completed)
+	}
+
+	reopened, err := s.UpdateContext(ctx, tk.ID, db.Changes{Status: ptr(task.InProgress.String())})
+	if err != nil {
+		t.Fatalf("Update to in progress: %v", err)
+	}
+	if !reopened.Completed.IsZero() {
+		t.Errorf("reopened task still has completion time %v", reopened.Completed)
+	}
+	got, err := s.GetTaskContext(ctx, tk.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Status != task.InProgress.String() || !got.Completed.IsZero() {
+		t.Errorf("stored reopened task = %+v", got)
+	}
+}
+
+func testTags(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	tagged, err := s.InsertTaskContext(ctx, task.Task{Name: "Buy milk", Tags: []string{"Errands", "home", "errands", " "}})
+	if err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	if got := strings.Join(tagged.Tags, ","); got != "errands,home" {
+		t.Errorf("inserted tags = %q, want errands,home", got)
+	}
+	mustInsert(t, s, "Untagged", "", task.Todo.String())
+	checkNames(t, mustList(t, s, db.ListOptions{Tag: "home"}), "Buy milk")
+	checkNames(t, mustList(t, s, db.ListOptions{Tag: "hom"}))
+
+	tags := []string{"shop"}
+	updated, err := s.UpdateContext(ctx, tagged.ID, db.Changes{Tags: &tags})
+	if err != nil {
+		t.Fatalf("Update tags: %v", err)
+	}
+	got, err := s.GetTaskContext(ctx, tagged.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if strings.Join(updated.Tags, ",") != "shop" || strings.Join(got.Tags, ",") != "shop" {
+		t.Errorf("tags after Update = %q, stored %q; want shop", updated.Tags, got.Tags)
+	}
+	checkNames(t, mustList(t, s, db.ListOptions{Tag: "home"}))
+
+	// Changing other fields keeps the tags.
+	if renamed, err := s.UpdateContext(ctx, tagged.ID, db.Changes{Name: ptr("Buy oat milk")}); err != nil || len(renamed.Tags) != 1 {
+		t.Errorf("Update name = %+v, %v; want the tags kept", renamed, err)
+	}
+	none := []string{}
+	if cleared, err := s.UpdateContext(ctx, tagged.ID, db.Changes{Tags: &none}); err != nil || len(cleared.Tags) != 0 {
+		t.Errorf("Update to no tags = %+v, %v; want the tags removed", cleared, err)
+	}
+}
+
+func testDue(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	// Due dates keep only the calendar day.
+	later, err := s.InsertTaskContext(ctx, task.Task{Name: "later", Due: time.Date(2026, 11, 2, 15, 30, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	if want := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC); !later.Due.Equal(want) {
+		t.Errorf("due = %v, want %v", later.Due, want)
+	}
+	mustInsert(t, s, "whenever", "", task.Todo.String())
+	sooner, err := s.InsertTaskContext(ctx, task.Task{Name: "sooner", Due: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+
+	// Tasks without a due date sort last.
+	checkNames(t, mustList(t, s, db.ListOptions{Sort: []db.SortKey{{Field: "due"}}}), "sooner", "later", "whenever")
+
+	none := time.Time{}
+	if _, err := s.UpdateContext(ctx, sooner.ID, db.Changes{Due: &none}); err != nil {
+		t.Fatalf("Update due: %v", err)
+	}
+	got, err := s.GetTaskContext(ctx, sooner.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if !got.Due.IsZero() {
+		t.Errorf("due after clearing = %v, want none", got.Due)
+	}
+}
+
+func testDelete(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	keep := mustInsert(t, s, "Keep", "", task.Todo.String())
+	gone := mustInsert(t, s, "Gone", "", task.Todo.String())
+	if err := s.DeleteContext(ctx, gone.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.GetTaskContext(ctx, gone.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("fetching a deleted task: err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteContext(ctx, gone.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("deleting a missing task: err = %v, want ErrNotFound", err)
+	}
+	checkNames(t, mustList(t, s, db.ListOptions{}), keep.Name)
+
+	time.Sleep(2 * time.Millisecond)
+	changed, err := s.UpdateContext(ctx, keep.ID, db.Changes{Project: ptr("moved")})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.DeleteIfUpdatedContext(ctx, keep.ID, keep.Updated); !errors.Is(err, db.ErrConflict) {
+		t.Errorf("deleting based on a stale copy: err = %v, want ErrConflict", err)
+	}
+	checkNames(t, mustList(t, s, db.ListOptions{}), keep.Name)
+	if err := s.DeleteIfUpdatedContext(ctx, keep.ID, changed.Updated); err != nil {
+		t.Errorf("deleting based on the current copy: %v", err)
+	}
+	checkNames(t, mustList(t, s, db.ListOptions{}))
+}
+
+func testRestore(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	orig := mustInsert(t, s, "Undo me", "p", task.Done.String())
+	mustInsert(t, s, "Other", "", task.Todo.String())
+	if err := s.DeleteContext(ctx, orig.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.RestoreContext(ctx, orig); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	got, err := s.GetTaskContext(ctx, orig.ID)
+	if err != nil {
+		t.Fatalf("GetTask after Restore: %v", err)
+	}
+	if got.Name != orig.Name || got.Project != orig.Project || got.Status != orig.Status ||
+		!got.Created.Equal(orig.Created) || !got.Completed.Equal(orig.Completed) || got.Position != orig.Position {
+		t.Errorf("restored task = %+v, want %+v", got, orig)
+	}
+	if err := s.RestoreContext(ctx, orig); !errors.Is(err, db.ErrConflict) {
+		t.Errorf("restoring a task whose ID is in use: err = %v, want ErrConflict", err)
+	}
+}
+
+func testDefaultOrder(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	mustInsert(t, s, "done", "", task.Done.String())
+	mustInsert(t, s, "todo 1", "", task.Todo.String())
+	mustInsert(t, s, "doing", "", task.InProgress.String())
+	mustInsert(t, s, "todo 2", "", task.Todo.String())
+
+	tasks, err := s.GetTasksContext(ctx)
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	checkNames(t, tasks, "todo 1", "todo 2", "doing", "done")
+}
+
+func testListFilters(t *testing.T, s db.Store) {
+	mustInsert(t, s, "a open", "alpha", task.Todo.String())
+	mustInsert(t, s, "a done", "alpha", task.Done.String())
+	mustInsert(t, s, "b open", "beta", task.InProgress.String())
+	mustInsert(t, s, "no project", "", task.Todo.String())
+
+	checkNames(t, mustList(t, s, db.ListOptions{Project: "alpha"}), "a open", "a done")
+	checkNames(t, mustList(t, s, db.ListOptions{HideDone: true}), "a open", "no project", "b open")
+	checkNames(t, mustList(t, s, db.ListOptions{Project: "alpha", HideDone: true}), "a open")
+	checkNames(t, mustList(t, s, db.ListOptions{Statuses: []string{task.InProgress.String(), task.Done.String()}}), "b open", "a done")
+
+	recent := mustList(t, s, db.ListOptions{DoneSince: time.Now().Add(-time.Hour)})
+	checkNames(t, recent, "a open", "no project", "b open", "a done")
+	future := mustList(t, s, db.ListOptions{DoneSince: time.Now().Add(time.Hour)})
+	checkNames(t, future, "a open", "no project", "b open")
+}
+
+func testListSort(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	mustInsert(t, s, "banana", "y", task.Todo.String())
+	mustInsert(t, s, "Apple", "x", task.Done.String())
+	mustInsert(t, s, "cherry", "x", task.Todo.String())
+
+	checkNames(t, mustList(t, s, db.ListOptions{Sort: []db.SortKey{{Field: "name"}}}), "Apple", "banana", "cherry")
+	checkNames(t, mustList(t, s, db.ListOptions{Sort: []db.SortKey{{Field: "name", Desc: true}}}), "cherry", "banana", "Apple")
+	checkNames(t, mustList(t, s, db.ListOptions{Sort: []db.SortKey{{Field: "project"}}}), "cherry", "Apple", "banana")
+	// IDs need not grow with each insert, but positions do.
+	checkNames(t, mustList(t, s, db.ListOptions{Sort: []db.SortKey{{Field: "position", Desc: true}}}), "cherry", "Apple", "banana")
+
+	if _, err := s.ListTasksContext(ctx, db.ListOptions{Sort: []db.SortKey{{Field: "nope"}}}); err == nil {
+		t.Error("ListTasks with an unknown sort field succeeded")
+	}
+}
+
+func testGetTasksByStatus(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	mustInsert(t, s, "first", "", task.Todo.String())
+	mustInsert(t, s, "other", "", task.Done.String())
+	time.Sleep(2 * time.Millisecond)
+	second := mustInsert(t, s, "second", "", task.Todo.String())
+	if err := s.MoveToTopContext(ctx, second.ID); err != nil {
+		t.Fatalf("MoveToTop: %v", err)
+	}
+
+	tasks, err := s.GetTasksByStatusContext(ctx, task.Todo.String())
+	if err != nil {
+		t.Fatalf("GetTasksByStatus: %v", err)
+	}
+	// Ordered by creation, not rank.
+	checkNames(t, tasks, "first", "second")
+}
+
+func testCounts(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	mustInsert(t, s, "1", "", task.Todo.String())
+	mustInsert(t, s, "2", "", task.Todo.String())
+	mustInsert(t, s, "3", "", task.Done.String())
+
+	counts, err := s.CountTasksByStatusContext(ctx)
+	if err != nil {
+		t.Fatalf("CountTasksByStatus: %v", err)
+	}
+	if counts[task.Todo.String()] != 2 || counts[task.Done.String()] != 1 || counts[task.InProgress.String()] != 0 {
+		t.Errorf("CountTasksByStatus = %v", counts)
+	}
+}
+
+func testProjectSummaries(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	oldest := mustInsert(t, s, "1", "web", task.Todo.String())
+	mustInsert(t, s, "2", "web", task.InProgress.String())
+	mustInsert(t, s, "3", "web", task.Done.String())
+	mustInsert(t, s, "4", "", task.Done.String())
+
+	summaries, err := s.GetProjectSummariesContext(ctx)
+	if err != nil {
+		t.Fatalf("GetProjectSummaries: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("got %d summaries, want 2: %+v", len(summaries), summaries)
+	}
+	none, web := summaries[0], summaries[1]
+	if none.Project != "" || none.Done != 1 || none.Total() != 1 || !none.OldestOpen.IsZero() {
+		t.Errorf("summary without project = %+v", none)
+	}
+	if web.Project != "web" || web.Todo != 1 || web.InProgress != 1 || web.Done != 1 {
+		t.Errorf("web summary = %+v", web)
+	}
+	// SQLite reports aggregated times with millisecond precision.
+	if d := web.OldestOpen.Sub(oldest.Created); d > time.Millisecond || d < -time.Millisecond {
+		t.Errorf("web oldest open = %v, want %v", web.OldestOpen, oldest.Created)
+	}
+	if web.LastActivity.Before(oldest.Created.Add(-time.Millisecond)) {
+		t.Errorf("web last activity %v is before the first task was created", web.LastActivity)
+	}
+}
+
+func testMove(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	a := mustInsert(t, s, "a", "", task.Todo.String())
+	b := mustInsert(t, s, "b", "", task.Todo.String())
+	c := mustInsert(t, s, "c", "", task.Todo.String())
+	d := mustInsert(t, s, "d", "", task.Todo.String())
+
+	steps := []struct {
+		desc string
+		move func() error
+		want []string
+	}{
+		{"c to top", func() error { return s.MoveToTopContext(ctx, c.ID) }, []string{"c", "a", "b", "d"}},
+		{"c to bottom", func() error { return s.MoveToBottomContext(ctx, c.ID) }, []string{"a", "b", "d", "c"}},
+		{"d before a", func() error { return s.MoveBeforeContext(ctx, d.ID, a.ID) }, []string{"d", "a", "b", "c"}},
+		{"d after b", func() error { return s.MoveAfterContext(ctx, d.ID, b.ID) }, []string{"a", "b", "d", "c"}},
+		{"a after c", func() error { return s.MoveAfterContext(ctx, a.ID, c.ID) }, []string{"b", "d", "c", "a"}},
+	}
+	for _, step := range steps {
+		if err := step.move(); err != nil {
+			t.Fatalf("%s: %v", step.desc, err)
+		}
+		checkNames(t, mustList(t, s, db.ListOptions{}), step.want...)
+	}
+
+	// Repeatedly halving the same gap forces a renumbering.
+	for i := 0; i < 60; i++ {
+		mover := b
+		if i%2 == 1 {
+			mover = c
+		}
+		if err := s.MoveBeforeContext(ctx, mover.ID, d.ID); err != nil {
+			t.Fatalf("MoveBefore round %d: %v", i, err)
+		}
+	}
+	checkNames(t, mustList(t, s, db.ListOptions{}), "b", "c", "d", "a")
+}
+
+func testMoveErrors(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	a := mustInsert(t, s, "a", "", task.Todo.String())
+	if err := s.MoveBeforeContext(ctx, a.ID, a.ID); !errors.Is(err, db.ErrInvalidInput) {
+		t.Errorf("moving a task before itself: err = %v, want ErrInvalidInput", err)
+	}
+	if err := s.MoveAfterContext(ctx, a.ID, a.ID+100); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("moving a task after a missing task: err = %v, want ErrNotFound", err)
+	}
+	if err := s.MoveToTopContext(ctx, a.ID+100); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("moving a missing task: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testImport(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	mustInsert(t, s, "Existing", "p", task.Todo.String())
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	completed := created.Add(48 * time.Hour)
+
+	result, err := s.ImportTasksContext(ctx, []task.Task{
+		{Name: "  existing ", Project: "p"},
+		{Name: "Fresh", Project: "p", Created: created},
+		{Name: "fresh", Project: "p"},
+		{Name: "Finished", Status: task.Done.String(), Created: created, Completed: completed},
+	}, db.ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportTasks: %v", err)
+	}
+	checkNames(t, result.Imported, "Fresh", "Finished")
+	checkNames(t, result.Duplicates, "existing", "fresh")
+
+	tasks := mustList(t, s, db.ListOptions{Sort: []db.SortKey{{Field: "position"}}})
+	checkNames(t, tasks, "Existing", "Fresh", "Finished")
+	fresh, finished := tasks[1], tasks[2]
+	if fresh.Status != task.Todo.String() || !fresh.Created.Equal(created) || !fresh.Updated.Equal(created) {
+		t.Errorf("imported todo task = %+v", fresh)
+	}
+	if !finished.Completed.Equal(completed) || !finished.Updated.Equal(completed) {
+		t.Errorf("imported done task = %+v", finished)
+	}
+	if result.Imported[0].ID != fresh.ID {
+		t.Errorf("ImportTasks reported ID %d, stored as %d", result.Imported[0].ID, fresh.ID)
+	}
+
+	again, err := s.ImportTasksContext(ctx, []task.Task{{Name: "Fresh", Project: "p"}}, db.ImportOptions{AllowDuplicates: true})
+	if err != nil {
+		t.Fatalf("ImportTasks with AllowDuplicates: %v", err)
+	}
+	if len(again.Imported) != 1 || len(again.Duplicates) != 0 {
+		t.Errorf("AllowDuplicates imported %d and skipped %d, want 1 and 0", len(again.Imported), len(again.Duplicates))
+	}
+}
+
+func testImportDryRun(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	result, err := s.ImportTasksContext(ctx, []task.Task{{Name: "Preview"}}, db.ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportTasks dry run: %v", err)
+	}
+	checkNames(t, result.Imported, "Preview")
+	if tasks := mustList(t, s, db.ListOptions{}); len(tasks) != 0 {
+		t.Errorf("dry run stored tasks: %q", names(tasks))
+	}
+}
+
+func testImportIsAtomic(t *testing.T, s db.Store) {
+	ctx := context.Background()
+	_, err := s.ImportTasksContext(ctx, []task.Task{
+		{Name: "Good"},
+		{Name: "Bad", Status: "doing"},
+	}, db.ImportOptions{})
+	if err == nil {
+		t.Fatal("ImportTasks with an invalid status succeeded")
+	}
+	if _, err := s.ImportTasksContext(ctx, []task.Task{{Name: "  "}}, db.ImportOptions{}); err == nil {
+		t.Fatal("ImportTasks with an empty name succeeded")
+	}
+	if tasks := mustList(t, s, db.ListOptions{}); len(tasks) != 0 {
+		t.Errorf("failed imports stored tasks: %q", names(tasks))
+	}
+}
+
+func testWatcher(t *testing.T, s db.Store) {
+	w, err := s.NewWatcher()
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Close()
+
+	if changed, err := w.Changed(); err != nil || changed {
+		t.Fatalf("Changed before any write = %v, %v; want false", changed, err)
+	}
+	mustInsert(t, s, "new", "", task.Todo.String())
+	if changed, err := w.Changed(); err != nil || !changed {
+		t.Fatalf("Changed after insert = %v, %v; want true", changed, err)
+	}
+	if changed, err := w.Changed(); err != nil || changed {
+		t.Fatalf("Changed after it was reported = %v, %v; want false", changed, err)
+	}
+}
+
+func testCancelledContext(t *testing.T, s db.Store) {
+	existing := mustInsert(t, s, "existing", "", task.Todo.String())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.InsertContext(ctx, "never", ""); err == nil {
+		t.Error("InsertContext with a cancelled context succeeded")
+	}
+	if _, err := s.UpdateContext(ctx, existing.ID, db.Changes{Name: ptr("renamed")}); err == nil {
+		t.Error("UpdateContext with a cancelled context succeeded")
+	}
+	if err := s.DeleteContext(ctx, existing.ID); err == nil {
+		t.Error("DeleteContext with a cancelled context succeeded")
+	}
+	if _, err := s.ListTasksContext(ctx, db.ListOptions{}); err == nil {
+		t.Error("ListTasksContext with a cancelled context succeeded")
+	}
+	checkNames(t, mustList(t, s, db.ListOptions{}), "existing")
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// ProjectSummary aggregates the tasks of a single project. Exported
+type ProjectSummary struct {
+	Project      string // Empty for tasks without a project
+	Todo         int
+	InProgress   int
+	Done         int
+	OldestOpen   time.Time // Creation time of the oldest unfinished task; zero if none
+	LastActivity time.Time // Most recent creation or modification of any task
+}
+
+// Total returns the number of tasks in the project.
+func (s ProjectSummary) Total() int {
+	return s.Todo + s.InProgress + s.Done
+}
+
+// Completion returns the fraction of tasks that are done, between 0 and 1.
+func (s ProjectSummary) Completion() float64 {
+	if s.Total() == 0 {
+		return 0
+	}
+	return float64(s.Done) / float64(s.Total())
+}
+
+// GetProjectSummaries aggregates tasks per project, ordered by project name.
+// Timestamps are compared as Julian days so values stored with different
+// timezone offsets still order correctly.
+func (tdb *TaskDB) GetProjectSummaries() ([]ProjectSummary, error) {
+	return tdb.GetProjectSummariesContext(context.Background())
+}
+
+// GetProjectSummariesContext is like GetProjectSummaries but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) GetProjectSummariesContext(ctx context.Context) ([]ProjectSummary, error) {
+	query := `
+	SELECT
+		COALESCE(project, '') AS proj,
+		SUM(status = ?),
+		SUM(status = ?),
+		SUM(status = ?),
+		MIN(CASE WHEN status != ? THEN julianday(created) END),
+		MAX(julianday(COALESCE(updated, created)))
+	FROM tasks
+	GROUP BY proj
+	ORDER BY proj ASC`
+
+	rows, err := tdb.db.QueryContext(ctx, query,
+		task.Todo.String(), task.InProgress.String(), task.Done.String(), task.Done.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to query project summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []ProjectSummary{}
+	for rows.Next() {
+		var s ProjectSummary
+		var oldestOpen, lastActivity sql.NullFloat64
+		err = rows.Scan(&s.Project, &s.Todo, &s.InProgress, &s.Done, &oldestOpen, &lastActivity)
+		if err != nil {
+			return nil, fmt.Errorf("failed scanning project summary row: %w", err)
+		}
+		if oldestOpen.Valid {
+			s.OldestOpen = julianToTime(oldestOpen.Float64)
+		}
+		if lastActivity.Valid {
+			s.LastActivity = julianToTime(lastActivity.Float64)
+		}
+		summaries = append(summaries, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating project summary rows: %w", err)
+	}
+	return summaries, nil
+}
+
+// julianToTime converts an SQLite Julian day number to a UTC time. (Unexported)
+func julianToTime(jd float64) time.Time {
+	// Julian day 2440587.5 is the Unix epoch.
+	secs := (jd - 2440587.5) * 86400
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(math.Round(frac*1e3))*int64(time.Millisecond)).UTC()
+}
+
+// CountTasksByStatus returns the number of tasks in each status.
+func (tdb *TaskDB) CountTasksByStatus() (map[string]int, error) {
+	return tdb.CountTasksByStatusContext(context.Background())
+}
+
+// CountTasksByStatusContext is like CountTasksByStatus but uses ctx for cancellation and deadlines.
+func (tdb *TaskDB) CountTasksByStatusContext(ctx context.Context) (map[string]int, error) {
+	rows, err := tdb.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM tasks GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("unable to count tasks by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed scanning status count row: %w", err)
+		}
+		counts[status] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status count rows: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Watcher detects commits made to the database by other connections,
+// including other taskly processes. Exported
+type Watcher struct {
+	conn *sql.Conn
+	last int64
+}
+
+// NewWatcher returns a Watcher holding its own connection, since SQLite's
+// data_version is only meaningful when read repeatedly on one connection.
+func (tdb *TaskDB) NewWatcher() (ChangeWatcher, error) {
+	conn, err := tdb.db.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watcher connection: %w", err)
+	}
+	w := &Watcher{conn: conn}
+	if w.last, err = w.dataVersion(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Changed reports whether the database was modified since the last call.
+func (w *Watcher) Changed() (bool, error) {
+	version, err := w.dataVersion()
+	if err != nil {
+		return false, err
+	}
+	changed := version != w.last
+	w.last = version
+	return changed, nil
+}
+
+// Close releases the watcher's connection.
+func (w *Watcher) Close() error {
+	return w.conn.Close()
+}
+
+// dataVersion reads PRAGMA data_version on the watcher's connection. (Unexported)
+func (w *Watcher) dataVersion() (int64, error) {
+	var version int64
+	if err := w.conn.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed reading data version: %w", err)
+	}
+	return version, nil
+}
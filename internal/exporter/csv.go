@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// csvHeader matches the columns read back by `taskly import --format csv`.
+var csvHeader = []string{"id", "name", "project", "status", "created", "updated", "completed", "tags", "due"}
+
+// writeCSV writes tasks as CSV with a header row. Times are RFC 3339 in UTC,
+// due dates are YYYY-MM-DD and tags are separated by commas.
+func writeCSV(w io.Writer, tasks []task.Task) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed writing CSV: %w", err)
+	}
+	for _, t := range tasks {
+		record := []string{
+			strconv.FormatUint(uint64(t.ID), 10),
+			t.Name,
+			t.Project,
+			t.Status,
+			csvTime(t.Created),
+			csvTime(t.Updated),
+			csvTime(t.Completed),
+			strings.Join(t.Tags, ","),
+			task.FormatDue(t.Due),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed writing CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed writing CSV: %w", err)
+	}
+	return nil
+}
+
+// csvTime formats t as RFC 3339 in UTC, or "" for the zero time.
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,42 @@
+// Package exporter writes tasks in formats understood by other tools.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Supported export formats.
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatTodoTxt  = "todotxt"
+	FormatMarkdown = "markdown"
+	FormatICS      = "ics"
+)
+
+// Formats returns the names accepted by Write. Exported
+func Formats() []string {
+	return []string{FormatJSON, FormatCSV, FormatTodoTxt, FormatMarkdown, FormatICS}
+}
+
+// Write encodes tasks in the given format, keeping their order. Exported
+func Write(format string, w io.Writer, tasks []task.Task) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, tasks)
+	case FormatCSV:
+		return writeCSV(w, tasks)
+	case FormatTodoTxt:
+		return writeTodoTxt(w, tasks)
+	case FormatMarkdown:
+		return writeMarkdown(w, tasks)
+	case FormatICS:
+		return writeICS(w, tasks)
+	default:
+		return fmt.Errorf("unknown export format %q (valid: %s)", format, strings.Join(Formats(), ", "))
+	}
+}
@@ -0,0 +1,121 @@
+package exporter_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ashish0kumar/taskly/internal/exporter"
+	"github.com/ashish0kumar/taskly/internal/importer"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// export writes tasks in format and returns the output.
+func export(t *testing.T, format string, tasks []task.Task) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := exporter.Write(format, &buf, tasks); err != nil {
+		t.Fatalf("Write(%s): %v", format, err)
+	}
+	return buf.String()
+}
+
+// sampleTasks covers the fields every format has to carry.
+func sampleTasks() []task.Task {
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	return []task.Task{
+		{ID: 1, Name: "Write release notes", Project: "v2", Status: task.Todo.String(), Tags: []string{"docs", "urgent"}, Due: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC), Created: created, Updated: created},
+		{ID: 2, Name: "Fix login", Status: task.Done.String(), Created: created, Updated: created.Add(time.Hour), Completed: created.Add(24 * time.Hour)},
+	}
+}
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	want := sampleTasks()
+	got, err := importer.Parse(importer.FormatTodoTxt, strings.NewReader(export(t, exporter.FormatTodoTxt, want)), importer.Options{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("round trip gave %d tasks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		if g.Name != w.Name || g.Project != w.Project || g.Status != w.Status ||
+			strings.Join(g.Tags, ",") != strings.Join(w.Tags, ",") || !g.Due.Equal(w.Due) {
+			t.Errorf("task %d came back as %+v, want %+v", i+1, g, w)
+		}
+		if g.Created.Format("2006-01-02") != w.Created.Format("2006-01-02") {
+			t.Errorf("task %d created %v, want the date of %v", i+1, g.Created, w.Created)
+		}
+		if w.Status == task.Done.String() && g.Completed.Format("2006-01-02") != w.Completed.Format("2006-01-02") {
+			t.Errorf("task %d completed %v, want the date of %v", i+1, g.Completed, w.Completed)
+		}
+	}
+}
+
+func TestCSVQuoting(t *testing.T) {
+	tricky := task.Task{ID: 3, Name: `Say "hi", then leave`, Project: "a,b", Status: task.Todo.String(), Tags: []string{"x", "y"}}
+	out := export(t, exporter.FormatCSV, []task.Task{tricky, {ID: 4, Name: "two\nlines", Status: task.Todo.String()}})
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV does not parse: %v\n%s", err, out)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want a header and 2 rows:\n%s", len(records), out)
+	}
+	if r := records[1]; r[1] != tricky.Name || r[2] != tricky.Project || r[7] != "x,y" {
+		t.Errorf("row = %q", r)
+	}
+	if records[2][1] != "two\nlines" {
+		t.Errorf("multi-line name came back as %q", records[2][1])
+	}
+
+	tasks, err := importer.Parse(importer.FormatCSV, strings.NewReader(out), importer.Options{})
+	if err != nil {
+		t.Fatalf("importing the export: %v", err)
+	}
+	if tasks[0].Name != tricky.Name || strings.Join(tasks[0].Tags, ",") != "x,y" {
+		t.Errorf("imported %+v, want %+v", tasks[0], tricky)
+	}
+}
+
+func TestICS(t *testing.T) {
+	long := strings.Repeat("Ünïcödé ", 20)
+	tasks := []task.Task{
+		{ID: 1, Name: "Call Bob; bring cake, plates\\forks\nand candles", Project: "Party, Inc", Status: task.Todo.String(), Tags: []string{"home"}, Due: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: long, Status: task.InProgress.String()},
+	}
+	out := export(t, exporter.FormatICS, tasks)
+
+	if !strings.HasSuffix(out, "\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Fatal("lines are not all terminated by CRLF")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets is not folded: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("fold splits a character: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		`SUMMARY:Call Bob\; bring cake\, plates\\forks\nand candles` + "\r\n",
+		`CATEGORIES:Party\, Inc,home` + "\r\n",
+		"DUE;VALUE=DATE:20261224\r\n",
+		"SUMMARY:" + long + "\r\n",
+		"STATUS:IN-PROCESS\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("unfolded output lacks %q:\n%s", want, unfolded)
+		}
+	}
+	if strings.Count(unfolded, "DUE;") != 1 {
+		t.Error("a task without a due date got a DUE property")
+	}
+}
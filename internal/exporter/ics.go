@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// icsTime is the UTC date-time format used by iCalendar (RFC 5545).
+const icsTime = "20060102T150405Z"
+
+// icsDate is the iCalendar DATE format, used for all-day due dates.
+const icsDate = "20060102"
+
+// icsLineLimit is the maximum line length in octets before folding.
+const icsLineLimit = 75
+
+// icsStatuses maps task statuses to VTODO STATUS values.
+var icsStatuses = map[string]string{
+	task.Todo.String():       "NEEDS-ACTION",
+	task.InProgress.String(): "IN-PROCESS",
+	task.Done.String():       "COMPLETED",
+}
+
+// writeICS writes a VCALENDAR with one VTODO per task, carrying its due
+// date as an all-day DUE and its creation, modification and completion
+// times; the project and tags become the VTODO's categories.
+func writeICS(w io.Writer, tasks []task.Task) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(icsTime)
+
+	writeICSLine(bw, "BEGIN:VCALENDAR")
+	writeICSLine(bw, "VERSION:2.0")
+	writeICSLine(bw, "PRODID:-//taskly//taskly//EN")
+	for _, t := range tasks {
+		writeICSLine(bw, "BEGIN:VTODO")
+		writeICSLine(bw, fmt.Sprintf("UID:task-%d@taskly", t.ID))
+		writeICSLine(bw, "DTSTAMP:"+stamp)
+		writeICSLine(bw, "SUMMARY:"+icsEscape(t.Name))
+		if categories := icsCategories(t); categories != "" {
+			writeICSLine(bw, "CATEGORIES:"+categories)
+		}
+		if !t.Due.IsZero() {
+			writeICSLine(bw, "DUE;VALUE=DATE:"+t.Due.UTC().Format(icsDate))
+		}
+		if status, ok := icsStatuses[t.Status]; ok {
+			writeICSLine(bw, "STATUS:"+status)
+		}
+		if !t.Created.IsZero() {
+			writeICSLine(bw, "CREATED:"+t.Created.UTC().Format(icsTime))
+		}
+		if !t.Updated.IsZero() {
+			writeICSLine(bw, "LAST-MODIFIED:"+t.Updated.UTC().Format(icsTime))
+		}
+		if !t.Completed.IsZero() {
+			writeICSLine(bw, "COMPLETED:"+t.Completed.UTC().Format(icsTime))
+			writeICSLine(bw, "PERCENT-COMPLETE:100")
+		}
+		writeICSLine(bw, "END:VTODO")
+	}
+	writeICSLine(bw, "END:VCALENDAR")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed writing iCalendar: %w", err)
+	}
+	return nil
+}
+
+// icsCategories returns the CATEGORIES value for t: its project followed
+// by its tags, each escaped and separated by commas.
+func icsCategories(t task.Task) string {
+	var values []string
+	if t.Project != "" {
+		values = append(values, icsEscape(t.Project))
+	}
+	for _, tag := range t.Tags {
+		values = append(values, icsEscape(tag))
+	}
+	return strings.Join(values, ",")
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it so no
+// physical line exceeds icsLineLimit octets. Folds never split a UTF-8
+// sequence. Errors are reported by the final Flush.
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = icsLineLimit - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// utf8Start reports whether b begins a UTF-8 encoded rune.
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// icsEscaper escapes TEXT values as required by RFC 5545.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsEscape makes s safe to use as an iCalendar TEXT value.
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// JSONTask is the JSON representation of a task, shared by the JSON export
+// and the HTTP API. Times are RFC 3339 in UTC, completed is omitted for
+// tasks that are not done and due, a YYYY-MM-DD date, for tasks without
+// one. Exported
+type JSONTask struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Project   string     `json:"project"`
+	Status    string     `json:"status"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	Completed *time.Time `json:"completed,omitempty"`
+	Position  float64    `json:"position"`
+	Tags      []string   `json:"tags"`
+	Due       string     `json:"due,omitempty"`
+}
+
+// ToJSON converts t to its JSON representation. Exported
+func ToJSON(t task.Task) JSONTask {
+	jt := JSONTask{
+		ID:       t.ID,
+		Name:     t.Name,
+		Project:  t.Project,
+		Status:   t.Status,
+		Created:  t.Created.UTC(),
+		Updated:  t.Updated.UTC(),
+		Position: t.Position,
+		Tags:     append([]string{}, t.Tags...), // Never null
+		Due:      task.FormatDue(t.Due),
+	}
+	if !t.Completed.IsZero() {
+		completed := t.Completed.UTC()
+		jt.Completed = &completed
+	}
+	return jt
+}
+
+// writeJSON writes tasks as an indented JSON array.
+func writeJSON(w io.Writer, tasks []task.Task) error {
+	out := make([]JSONTask, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, ToJSON(t))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed writing JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// noProjectHeading titles the section for tasks without a project.
+const noProjectHeading = "No project"
+
+// writeMarkdown writes a checklist with one "## project" section per project,
+// in order of first appearance. Done tasks are checked; tasks in progress
+// are marked in italics after their name, followed by the due date and any
+// #tags.
+func writeMarkdown(w io.Writer, tasks []task.Task) error {
+	var order []string
+	byProject := map[string][]task.Task{}
+	for _, t := range tasks {
+		if _, ok := byProject[t.Project]; !ok {
+			order = append(order, t.Project)
+		}
+		byProject[t.Project] = append(byProject[t.Project], t)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, project := range order {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		heading := project
+		if heading == "" {
+			heading = noProjectHeading
+		}
+		fmt.Fprintf(bw, "## %s\n\n", heading)
+		for _, t := range byProject[project] {
+			box := "[ ]"
+			if t.Status == task.Done.String() {
+				box = "[x]"
+			}
+			line := fmt.Sprintf("- %s %s", box, markdownEscape(t.Name))
+			if t.Status == task.InProgress.String() {
+				line += " _(in progress)_"
+			}
+			if !t.Due.IsZero() {
+				line += " (due " + task.FormatDue(t.Due) + ")"
+			}
+			for _, tag := range t.Tags {
+				line += " #" + markdownEscape(tag)
+			}
+			fmt.Fprintln(bw, line)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed writing Markdown: %w", err)
+	}
+	return nil
+}
+
+// markdownEscaper escapes characters that would otherwise change how a task
+// name renders inside a list item.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, "\n", " ",
+)
+
+// markdownEscape makes s safe to use as list item text.
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// todoTxtDate is the date format used in todo.txt files.
+const todoTxtDate = "2006-01-02"
+
+// writeTodoTxt writes one todo.txt line per task. Done tasks are marked "x"
+// with their completion date, every task carries its creation date, and the
+// project becomes a +project tag, tags become @contexts and the due date a
+// due:YYYY-MM-DD tag. todo.txt has no "in progress" state, so
+// those tasks are written as open.
+func writeTodoTxt(w io.Writer, tasks []task.Task) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tasks {
+		var parts []string
+		if t.Status == task.Done.String() {
+			parts = append(parts, "x")
+			if !t.Completed.IsZero() {
+				parts = append(parts, t.Completed.Local().Format(todoTxtDate))
+			}
+		}
+		if !t.Created.IsZero() {
+			parts = append(parts, t.Created.Local().Format(todoTxtDate))
+		}
+		parts = append(parts, strings.Join(strings.Fields(t.Name), " "))
+		if t.Project != "" {
+			// Project tags cannot contain spaces.
+			parts = append(parts, "+"+strings.Join(strings.Fields(t.Project), "_"))
+		}
+		for _, tag := range t.Tags {
+			parts = append(parts, "@"+tag)
+		}
+		if !t.Due.IsZero() {
+			parts = append(parts, "due:"+task.FormatDue(t.Due))
+		}
+		if _, err := fmt.Fprintln(bw, strings.Join(parts, " ")); err != nil {
+			return fmt.Errorf("failed writing todo.txt: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed writing todo.txt: %w", err)
+	}
+	return nil
+}
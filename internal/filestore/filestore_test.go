@@ -0,0 +1,49 @@
+package filestore_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/db/storetest"
+	"github.com/ashish0kumar/taskly/internal/filestore"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store {
+		s, err := filestore.Open(filepath.Join(t.TempDir(), filestore.DirName))
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		return s
+	})
+}
+
+func TestIDsDoNotCollideAcrossBranches(t *testing.T) {
+	ctx := context.Background()
+	var ids []uint
+	for _, branch := range []string{"main", "feature"} {
+		// Both branches start from the same single task.
+		s, err := filestore.Open(filepath.Join(t.TempDir(), branch, filestore.DirName))
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if err := s.RestoreContext(ctx, task.Task{ID: 1, Name: "Shared", Status: task.Todo.String(), Created: time.Now()}); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		added, err := s.InsertContext(ctx, "Added on "+branch, "")
+		if err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		if added.ID < 100000 {
+			t.Errorf("new task on %s got ID %d, want at least six digits", branch, added.ID)
+		}
+		ids = append(ids, added.ID)
+	}
+	if ids[0] == ids[1] {
+		t.Errorf("tasks added on two branches share ID %d", ids[0])
+	}
+}
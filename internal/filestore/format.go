@@ -0,0 +1,184 @@
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// frontMatterDelim opens and closes the YAML front matter of a task file.
+const frontMatterDelim = "---"
+
+// record is a task together with the free-form Markdown below its front
+// matter, which taskly keeps untouched so files can hold notes.
+type record struct {
+	task.Task
+	body string
+}
+
+// encode renders a task file: YAML front matter with one field per line in a
+// fixed order, so edits produce small, line-based diffs.
+func encode(r record) []byte {
+	var b bytes.Buffer
+	b.WriteString(frontMatterDelim + "\n")
+	fmt.Fprintf(&b, "id: %d\n", r.ID)
+	fmt.Fprintf(&b, "name: %s\n", strconv.Quote(r.Name))
+	fmt.Fprintf(&b, "project: %s\n", strconv.Quote(r.Project))
+	fmt.Fprintf(&b, "status: %s\n", r.Status)
+	fmt.Fprintf(&b, "created: %s\n", formatTime(r.Created))
+	fmt.Fprintf(&b, "updated: %s\n", formatTime(r.Updated))
+	if !r.Completed.IsZero() {
+		fmt.Fprintf(&b, "completed: %s\n", formatTime(r.Completed))
+	}
+	fmt.Fprintf(&b, "position: %s\n", strconv.FormatFloat(r.Position, 'f', -1, 64))
+	if len(r.Tags) > 0 {
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(r.Tags, ", "))
+	}
+	if !r.Due.IsZero() {
+		fmt.Fprintf(&b, "due: %s\n", task.FormatDue(r.Due))
+	}
+	b.WriteString(frontMatterDelim + "\n")
+	b.WriteString(r.body)
+	return b.Bytes()
+}
+
+// decode parses a task file written by encode, or edited by hand. Unknown
+// front matter keys are ignored.
+func decode(data []byte) (record, error) {
+	var r record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != frontMatterDelim {
+		return r, fmt.Errorf("missing front matter")
+	}
+
+	closed := false
+	for line := 2; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == frontMatterDelim {
+			closed = true
+			break
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(strings.TrimSpace(text), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return r, fmt.Errorf("line %d: expected \"key: value\"", line)
+		}
+		if err := setField(&r.Task, strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return r, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return r, err
+	}
+	if !closed {
+		return r, fmt.Errorf("front matter is not closed with %q", frontMatterDelim)
+	}
+
+	// Keep everything after the closing delimiter byte for byte.
+	start := bytes.Index(data, []byte("\n"+frontMatterDelim))
+	rest := data[start+1+len(frontMatterDelim):]
+	if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+		r.body = string(rest[i+1:])
+	}
+	return r, nil
+}
+
+// setField assigns one front matter value to the matching task field.
+func setField(t *task.Task, key, value string) error {
+	var err error
+	switch key {
+	case "id":
+		var id uint64
+		id, err = strconv.ParseUint(value, 10, 32)
+		t.ID = uint(id)
+	case "name":
+		t.Name, err = unquote(value)
+	case "project":
+		t.Project, err = unquote(value)
+	case "status":
+		t.Status, err = unquote(value)
+	case "created":
+		t.Created, err = parseTime(value)
+	case "updated":
+		t.Updated, err = parseTime(value)
+	case "completed":
+		t.Completed, err = parseTime(value)
+	case "position":
+		t.Position, err = strconv.ParseFloat(value, 64)
+	case "tags":
+		t.Tags, err = parseTags(value)
+	case "due":
+		t.Due, err = parseDue(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+// unquote accepts YAML plain, single-quoted and double-quoted scalars.
+func unquote(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+// parseTags reads a YAML flow sequence of tags such as [home, "errands"],
+// or a bare comma-separated list.
+func parseTags(value string) ([]string, error) {
+	if strings.HasPrefix(value, "[") {
+		if !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("unterminated list %s", value)
+		}
+		value = value[1 : len(value)-1]
+	}
+	var tags []string
+	for _, item := range strings.Split(value, ",") {
+		tag, err := unquote(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return task.NormalizeTags(tags), nil
+}
+
+// parseDue reads a YYYY-MM-DD due date; an empty value means none.
+func parseDue(value string) (time.Time, error) {
+	value, err := unquote(value)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+	return time.Parse(task.DueLayout, value)
+}
+
+// formatTime writes times in UTC with full precision.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime reads a time written by formatTime, or a plain date.
+func parseTime(value string) (time.Time, error) {
+	value, err := unquote(value)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,578 @@
+// Package filestore stores tasks as plain-text files that can be versioned
+// with the code they belong to. Each task is a Markdown file named after its
+// ID, with its fields in YAML front matter, so changes to different tasks
+// never conflict when merging. New tasks get random IDs rather than the next
+// number, so tasks added on different branches keep apart too.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// DirName is the directory holding task files, looked up like .git.
+const DirName = ".taskly"
+
+// fileExt is the extension of task files.
+const fileExt = ".md"
+
+// Store keeps tasks in a directory of task files. Exported
+type Store struct {
+	dir string
+}
+
+// Store must satisfy db.Store.
+var _ db.Store = (*Store)(nil)
+
+// FindDir returns the nearest DirName directory in start or one of its
+// parents, or DirName inside start if there is none yet. Exported
+func FindDir(start string) (string, error) {
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for dir := start; ; dir = filepath.Dir(dir) {
+		candidate := filepath.Join(dir, DirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return filepath.Join(start, DirName), nil
+}
+
+// Open returns a Store for dir. The directory is created on the first write. Exported
+func Open(dir string) (*Store, error) {
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("task directory '%s' is not a directory", dir)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory holding the task files.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Close releases the store. Files are written immediately, so there is
+// nothing to flush.
+func (s *Store) Close() error {
+	return nil
+}
+
+// path returns the file name for a task ID.
+func (s *Store) path(id uint) string {
+	return filepath.Join(s.dir, strconv.FormatUint(uint64(id), 10)+fileExt)
+}
+
+// load reads every task file, ordered by ID.
+func (s *Store) load() ([]record, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read task directory '%s': %w", s.dir, err)
+	}
+
+	var records []record
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, fileExt), 10, 32)
+		if err != nil {
+			continue // Not a task file, e.g. a README.
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read task file '%s': %w", name, err)
+		}
+		r, err := decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task file '%s': %w", name, err)
+		}
+		// The file name is authoritative, so renaming a file renumbers the task.
+		r.ID = uint(id)
+		if r.Status == "" {
+			r.Status = task.Todo.String()
+		}
+		if r.Updated.IsZero() {
+			r.Updated = r.Created
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	return records, nil
+}
+
+// loadTasks reads every task without its notes.
+func (s *Store) loadTasks() ([]task.Task, error) {
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]task.Task, len(records))
+	for i, r := range records {
+		tasks[i] = r.Task
+	}
+	return tasks, nil
+}
+
+// find returns the record for id.
+func (s *Store) find(id uint) (record, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return record{}, db.Errorf(db.ErrNotFound, "task with ID %d not found", id)
+		}
+		return record{}, fmt.Errorf("failed querying task %d: %w", id, err)
+	}
+	r, err := decode(data)
+	if err != nil {
+		return record{}, fmt.Errorf("invalid task file for task %d: %w", id, err)
+	}
+	r.ID = id
+	if r.Updated.IsZero() {
+		r.Updated = r.Created
+	}
+	return r, nil
+}
+
+// write replaces a task file atomically, so readers never see half a file.
+func (s *Store) write(r record) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("could not create task directory '%s': %w", s.dir, err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed writing task %d: %w", r.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(encode(r)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed writing task %d: %w", r.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed writing task %d: %w", r.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(r.ID)); err != nil {
+		return fmt.Errorf("failed writing task %d: %w", r.ID, err)
+	}
+	return nil
+}
+
+// create stores r under a new random ID. Random IDs keep tasks added on
+// different branches from claiming the same file, and the file is created
+// exclusively, so concurrent processes never share an ID either.
+func (s *Store) create(r record, existing []task.Task) (task.Task, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return task.Task{}, fmt.Errorf("could not create task directory '%s': %w", s.dir, err)
+	}
+	for {
+		id := randomID(len(existing))
+		f, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return task.Task{}, fmt.Errorf("insert failed: %w", err)
+		}
+		f.Close()
+		r.ID = id
+		if err := s.write(r); err != nil {
+			os.Remove(s.path(id))
+			return task.Task{}, fmt.Errorf("insert failed: %w", err)
+		}
+		return r.Task, nil
+	}
+}
+
+// Bounds on the number of digits in a new task ID.
+const (
+	minIDDigits = 6
+	maxIDDigits = 9 // IDs must fit in 32 bits
+)
+
+// randomID returns a random ID for a store holding count tasks, with enough
+// digits that two IDs drawn independently, say on two branches, are
+// unlikely to match: at least minIDDigits, and four more than count has.
+func randomID(count int) uint {
+	digits := len(strconv.Itoa(count)) + 4
+	if digits < minIDDigits {
+		digits = minIDDigits
+	}
+	if digits > maxIDDigits {
+		digits = maxIDDigits
+	}
+	low := int64(1)
+	for i := 1; i < digits; i++ {
+		low *= 10
+	}
+	return uint(low + rand.Int63n(9*low))
+}
+
+// --- db.Store implementation ---
+
+// InsertContext adds a new task.
+func (s *Store) InsertContext(ctx context.Context, name, project string) (task.Task, error) {
+	return s.InsertTaskContext(ctx, task.Task{Name: name, Project: project})
+}
+
+// InsertTaskContext adds a new task with the name, project, status and tags
+// of t, which starts as todo if t has no status.
+func (s *Store) InsertTaskContext(ctx context.Context, t task.Task) (task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return task.Task{}, err
+	}
+	t, err := db.PrepareInsert(t, time.Now().UTC())
+	if err != nil {
+		return task.Task{}, fmt.Errorf("insert failed: %w", err)
+	}
+
+	existing, err := s.loadTasks()
+	if err != nil {
+		return task.Task{}, err
+	}
+	t.Position = db.NextPosition(existing)
+	return s.create(record{Task: t}, existing)
+}
+
+// RestoreContext re-inserts a previously deleted task, keeping its ID and timestamps.
+func (s *Store) RestoreContext(ctx context.Context, t task.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(s.path(t.ID)); err == nil {
+		return db.Errorf(db.ErrConflict, "restore failed for id %d: task already exists", t.ID)
+	}
+	if err := db.ValidateTask(t); err != nil {
+		return fmt.Errorf("restore failed for id %d: %w", t.ID, err)
+	}
+	t.Tags = task.NormalizeTags(t.Tags)
+	t.Due = task.Day(t.Due)
+	return s.write(record{Task: t})
+}
+
+// UpdateContext modifies an existing task.
+func (s *Store) UpdateContext(ctx context.Context, id uint, ch db.Changes) (task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return task.Task{}, err
+	}
+	r, err := s.find(id)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("cannot update task %d: %w", id, err)
+	}
+	if err := db.CheckUnchanged(r.Task, ch); err != nil {
+		return task.Task{}, err
+	}
+	if !db.ApplyUpdate(&r.Task, ch, time.Now().UTC()) {
+		return r.Task, nil
+	}
+	if err := db.ValidateTask(r.Task); err != nil {
+		return task.Task{}, fmt.Errorf("update failed for id %d: %w", id, err)
+	}
+	if err := s.write(r); err != nil {
+		return task.Task{}, err
+	}
+	return r.Task, nil
+}
+
+// DeleteContext removes a task by ID.
+func (s *Store) DeleteContext(ctx context.Context, id uint) error {
+	return s.DeleteIfUpdatedContext(ctx, id, time.Time{})
+}
+
+// DeleteIfUpdatedContext removes a task by ID if it was last updated at
+// updated, or whenever updated is zero.
+func (s *Store) DeleteIfUpdatedContext(ctx context.Context, id uint, updated time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !updated.IsZero() {
+		r, err := s.find(id)
+		if errors.Is(err, db.ErrNotFound) {
+			return db.Errorf(db.ErrNotFound, "task with ID %d not found for deletion", id)
+		} else if err != nil {
+			return err
+		}
+		if err := db.CheckUnchanged(r.Task, db.Changes{IfUpdated: updated}); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(s.path(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return db.Errorf(db.ErrNotFound, "task with ID %d not found for deletion", id)
+		}
+		return fmt.Errorf("delete failed for id %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetTaskContext retrieves a single task by ID.
+func (s *Store) GetTaskContext(ctx context.Context, id uint) (task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return task.Task{}, err
+	}
+	r, err := s.find(id)
+	if err != nil {
+		return task.Task{}, err
+	}
+	return r.Task, nil
+}
+
+// GetTasksContext retrieves all tasks, ordered by manual rank within status.
+func (s *Store) GetTasksContext(ctx context.Context) ([]task.Task, error) {
+	return s.ListTasksContext(ctx, db.ListOptions{})
+}
+
+// GetTasksByStatusContext retrieves tasks filtered by status, oldest first.
+func (s *Store) GetTasksByStatusContext(ctx context.Context, status string) ([]task.Task, error) {
+	tasks, err := s.ListTasksContext(ctx, db.ListOptions{Sort: []db.SortKey{{Field: "created"}}})
+	if err != nil {
+		return nil, err
+	}
+	filtered := []task.Task{}
+	for _, t := range tasks {
+		if t.Status == status {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// ListTasksContext retrieves tasks according to the given options.
+func (s *Store) ListTasksContext(ctx context.Context, opts db.ListOptions) ([]task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return nil, err
+	}
+	return opts.Apply(tasks)
+}
+
+// CountTasksByStatusContext returns the number of tasks in each status.
+func (s *Store) CountTasksByStatusContext(ctx context.Context) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return nil, err
+	}
+	return db.CountByStatus(tasks), nil
+}
+
+// GetProjectSummariesContext aggregates tasks per project, ordered by project name.
+func (s *Store) GetProjectSummariesContext(ctx context.Context) ([]db.ProjectSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return nil, err
+	}
+	return db.SummarizeProjects(tasks), nil
+}
+
+// MoveToTopContext ranks a task before every other task.
+func (s *Store) MoveToTopContext(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, 0, db.PlaceTop)
+}
+
+// MoveToBottomContext ranks a task after every other task.
+func (s *Store) MoveToBottomContext(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, 0, db.PlaceBottom)
+}
+
+// MoveBeforeContext ranks a task immediately before another one.
+func (s *Store) MoveBeforeContext(ctx context.Context, id, otherID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, otherID, db.PlaceBefore)
+}
+
+// MoveAfterContext ranks a task immediately after another one.
+func (s *Store) MoveAfterContext(ctx context.Context, id, otherID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, otherID, db.PlaceAfter)
+}
+
+// rerank moves a task and rewrites every file whose position changed.
+func (s *Store) rerank(id, otherID uint, place db.Placement) error {
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	tasks := make([]task.Task, len(records))
+	for i, r := range records {
+		tasks[i] = r.Task
+	}
+	if err := db.Rerank(tasks, id, otherID, place); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for i, r := range records {
+		if r.ID == id {
+			r.Updated = now
+		} else if tasks[i].Position == r.Position {
+			continue
+		}
+		r.Position = tasks[i].Position
+		if err := s.write(r); err != nil {
+			return fmt.Errorf("move failed for id %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// ImportTasksContext adds tasks with the same defaults and duplicate detection as
+// the SQLite store. Every task is validated before any file is written, and
+// files already written are removed again if a later write fails.
+func (s *Store) ImportTasksContext(ctx context.Context, tasks []task.Task, opts db.ImportOptions) (db.ImportResult, error) {
+	var result db.ImportResult
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	existing, err := s.loadTasks()
+	if err != nil {
+		return result, err
+	}
+
+	seen := map[string]bool{}
+	for _, t := range existing {
+		seen[db.DuplicateKey(t.Name, t.Project)] = true
+	}
+	now := time.Now().UTC()
+	var pending []task.Task
+	for i, t := range tasks {
+		t, err := db.PrepareImport(t, i, now)
+		if err != nil {
+			return result, err
+		}
+		key := db.DuplicateKey(t.Name, t.Project)
+		if seen[key] && !opts.AllowDuplicates {
+			result.Duplicates = append(result.Duplicates, t)
+			continue
+		}
+		seen[key] = true
+		pending = append(pending, t)
+	}
+
+	position := db.NextPosition(existing)
+	if opts.DryRun {
+		taken := map[uint]bool{}
+		for _, t := range existing {
+			taken[t.ID] = true
+		}
+		for _, t := range pending {
+			t.ID = randomID(len(taken))
+			for taken[t.ID] {
+				t.ID = randomID(len(taken))
+			}
+			taken[t.ID] = true
+			t.Position = position
+			position = db.NextPosition([]task.Task{t})
+			result.Imported = append(result.Imported, t)
+		}
+		return result, nil
+	}
+
+	all := existing
+	for _, t := range pending {
+		t.Position = position
+		created, err := s.create(record{Task: t}, all)
+		if err != nil {
+			for _, done := range result.Imported {
+				os.Remove(s.path(done.ID))
+			}
+			result.Imported = nil
+			return result, fmt.Errorf("task %q: %w", t.Name, err)
+		}
+		all = append(all, created)
+		position = db.NextPosition([]task.Task{created})
+		result.Imported = append(result.Imported, created)
+	}
+	return result, nil
+}
+
+// NewWatcher returns a watcher that notices files being added, removed or
+// modified in the task directory.
+func (s *Store) NewWatcher() (db.ChangeWatcher, error) {
+	w := &watcher{dir: s.dir}
+	var err error
+	if w.last, err = w.snapshot(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// watcher detects changes by comparing directory listings.
+type watcher struct {
+	dir  string
+	last string
+}
+
+// Changed reports whether any task file changed since the last call.
+func (w *watcher) Changed() (bool, error) {
+	snap, err := w.snapshot()
+	if err != nil {
+		return false, err
+	}
+	changed := snap != w.last
+	w.last = snap
+	return changed, nil
+}
+
+// Close releases the watcher.
+func (w *watcher) Close() error {
+	return nil
+}
+
+// snapshot summarises the names, sizes and modification times of the task files.
+func (w *watcher) snapshot() (string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read task directory '%s': %w", w.dir, err)
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // Removed since listing; the next poll sees it gone.
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", e.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
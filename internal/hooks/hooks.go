@@ -0,0 +1,378 @@
+// Package hooks runs user scripts before and after tasks are added, updated
+// and deleted, in the style of Taskwarrior's hooks.
+//
+// A hook is an executable in the hooks directory named after the event it
+// handles, optionally followed by a hyphen and anything else, such as
+// pre-add or post-delete-notify; hooks for the same event run in name
+// order. Files without execute permission and backups such as pre-add~ or
+// pre-add.orig are skipped. Each hook receives the task as a JSON object on stdin, in the
+// format of 'taskly export --format json', and TASKLY_HOOK names the event.
+//
+//   - pre-add and pre-update receive the task as it is about to be saved. A
+//     non-zero exit aborts the change, with the hook's stderr as the reason;
+//     printing a JSON object on stdout replaces the task's name, project,
+//     status, tags or due date with the values it contains, for the next
+//     hook and the store.
+//   - pre-delete receives the task about to be deleted, and aborts the
+//     deletion by exiting non-zero. Its stdout is ignored.
+//   - post-add, post-update and post-delete receive the task as saved (or as
+//     it was, for deletions). They cannot undo the change, so their failures
+//     are only reported as warnings.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/exporter"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Events that run hooks. Exported
+const (
+	PreAdd     = "pre-add"
+	PostAdd    = "post-add"
+	PreUpdate  = "pre-update"
+	PostUpdate = "post-update"
+	PreDelete  = "pre-delete"
+	PostDelete = "post-delete"
+)
+
+// EventEnv is the environment variable naming the event a hook runs for.
+// Exported
+const EventEnv = "TASKLY_HOOK"
+
+// backupSuffixes end the names of files editors, patch tools and package
+// managers leave next to a script, which must not run as hooks themselves.
+var backupSuffixes = []string{"~", ".bak", ".old", ".orig", ".rej", ".swp", ".tmp", ".dpkg-old", ".dpkg-new", ".rpmsave", ".rpmnew", ".sample"}
+
+// isHookFor reports whether a file named name in the hooks directory is a
+// hook for event: named event or event-<anything>, and not a backup. On
+// Windows, where executables need one, the extension is ignored.
+func isHookFor(name, event string) bool {
+	for _, suffix := range backupSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name == event || strings.HasPrefix(name, event+"-")
+}
+
+// Find returns the paths of the hooks for event in dir, in the order they
+// run. A missing directory has no hooks. Exported
+func Find(dir, event string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read hooks directory '%s': %w", dir, err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isHookFor(entry.Name(), event) {
+			continue
+		}
+		// Stat follows symlinks, so linked scripts work.
+		info, err := os.Stat(filepath.Join(dir, entry.Name()))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Store runs the hooks in a directory around the changes made through the
+// store it wraps. Moves, imports and restoring deleted tasks do not run
+// hooks. Exported
+type Store struct {
+	db.Store
+	dir      string
+	warnings io.Writer
+}
+
+// Wrap returns store running the hooks in dir, reporting the failures of
+// post-hooks to warnings. Exported
+func Wrap(store db.Store, dir string, warnings io.Writer) *Store {
+	return &Store{Store: store, dir: dir, warnings: warnings}
+}
+
+// Unwrap returns the wrapped store. Exported
+func (s *Store) Unwrap() db.Store {
+	return s.Store
+}
+
+// InsertContext adds a todo task, running the add hooks. Exported
+func (s *Store) InsertContext(ctx context.Context, name, project string) (task.Task, error) {
+	return s.InsertTaskContext(ctx, task.Task{Name: name, Project: project})
+}
+
+// Guard vets a change once the pre-hooks have had their say, given the task
+// as it was, or the zero task for an add, and as it is about to be saved.
+// Returning an error stops the change. Exported
+type Guard func(ctx context.Context, before, after task.Task) error
+
+// Insert adds t to store like InsertTaskContext, refusing it if guard
+// returns an error for the task the pre-add hooks leave, when store runs
+// hooks. Exported
+func Insert(ctx context.Context, store db.Store, t task.Task, guard Guard) (task.Task, error) {
+	if s, ok := store.(*Store); ok {
+		return s.insert(ctx, t, guard)
+	}
+	if t.Status == "" {
+		t.Status = task.Todo.String()
+	}
+	if err := guard(ctx, task.Task{}, t); err != nil {
+		return task.Task{}, err
+	}
+	return store.InsertTaskContext(ctx, t)
+}
+
+// Update changes a task in store like UpdateContext, refusing the change
+// if guard returns an error for the task the pre-update hooks leave, when
+// store runs hooks. Exported
+func Update(ctx context.Context, store db.Store, id uint, ch db.Changes, guard Guard) (task.Task, error) {
+	if s, ok := store.(*Store); ok {
+		return s.update(ctx, id, ch, guard)
+	}
+	current, err := store.GetTaskContext(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+	proposed := current
+	db.ApplyUpdate(&proposed, ch, current.Updated)
+	if err := guard(ctx, current, proposed); err != nil {
+		return task.Task{}, err
+	}
+	return store.UpdateContext(ctx, id, ch)
+}
+
+// InsertTaskContext adds a task, running the add hooks. Exported
+func (s *Store) InsertTaskContext(ctx context.Context, t task.Task) (task.Task, error) {
+	return s.insert(ctx, t, nil)
+}
+
+// insert adds a task, running the add hooks and then guard, if not nil.
+func (s *Store) insert(ctx context.Context, t task.Task, guard Guard) (task.Task, error) {
+	if t.Status == "" {
+		t.Status = task.Todo.String()
+	}
+	proposed, err := s.pre(ctx, PreAdd, t, true)
+	if err != nil {
+		return task.Task{}, err
+	}
+	if guard != nil {
+		if err := guard(ctx, task.Task{}, proposed); err != nil {
+			return task.Task{}, err
+		}
+	}
+	added, err := s.Store.InsertTaskContext(ctx, proposed)
+	if err != nil {
+		return added, err
+	}
+	s.post(ctx, PostAdd, added)
+	return added, nil
+}
+
+// UpdateContext changes a task, running the update hooks. Exported
+func (s *Store) UpdateContext(ctx context.Context, id uint, ch db.Changes) (task.Task, error) {
+	return s.update(ctx, id, ch, nil)
+}
+
+// update changes a task, running the update hooks and then guard, if not
+// nil.
+func (s *Store) update(ctx context.Context, id uint, ch db.Changes, guard Guard) (task.Task, error) {
+	pre, err := Find(s.dir, PreUpdate)
+	if err != nil {
+		return task.Task{}, err
+	}
+	if len(pre) > 0 || guard != nil {
+		current, err := s.Store.GetTaskContext(ctx, id)
+		if err != nil {
+			return task.Task{}, err
+		}
+		proposed := current
+		db.ApplyUpdate(&proposed, ch, current.Updated)
+		if proposed, err = s.run(ctx, pre, PreUpdate, proposed, true); err != nil {
+			return task.Task{}, err
+		}
+		if guard != nil {
+			if err := guard(ctx, current, proposed); err != nil {
+				return task.Task{}, err
+			}
+		}
+		// Pass on the fields asked for and those the hooks changed.
+		if ch.Name != nil || proposed.Name != current.Name {
+			ch.Name = &proposed.Name
+		}
+		if ch.Project != nil || proposed.Project != current.Project {
+			ch.Project = &proposed.Project
+		}
+		if ch.Status != nil || proposed.Status != current.Status {
+			ch.Status = &proposed.Status
+		}
+		if ch.Tags != nil || strings.Join(proposed.Tags, " ") != strings.Join(current.Tags, " ") {
+			ch.Tags = &proposed.Tags
+		}
+		if ch.Due != nil || !proposed.Due.Equal(current.Due) {
+			ch.Due = &proposed.Due
+		}
+	}
+	t, err := s.Store.UpdateContext(ctx, id, ch)
+	if err != nil {
+		return t, err
+	}
+	s.post(ctx, PostUpdate, t)
+	return t, nil
+}
+
+// DeleteContext deletes a task, running the delete hooks. Exported
+func (s *Store) DeleteContext(ctx context.Context, id uint) error {
+	return s.DeleteIfUpdatedContext(ctx, id, time.Time{})
+}
+
+// DeleteIfUpdatedContext deletes a task if it was last updated at updated,
+// or whenever updated is zero, running the delete hooks. Exported
+func (s *Store) DeleteIfUpdatedContext(ctx context.Context, id uint, updated time.Time) error {
+	pre, err := Find(s.dir, PreDelete)
+	if err != nil {
+		return err
+	}
+	post, err := Find(s.dir, PostDelete)
+	if err != nil {
+		return err
+	}
+	if len(pre) == 0 && len(post) == 0 {
+		return s.Store.DeleteIfUpdatedContext(ctx, id, updated)
+	}
+
+	t, err := s.Store.GetTaskContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.run(ctx, pre, PreDelete, t, false); err != nil {
+		return err
+	}
+	if err := s.Store.DeleteIfUpdatedContext(ctx, id, updated); err != nil {
+		return err
+	}
+	s.post(ctx, PostDelete, t)
+	return nil
+}
+
+// pre runs the pre-hooks for event on t, returning the task they leave.
+func (s *Store) pre(ctx context.Context, event string, t task.Task, editable bool) (task.Task, error) {
+	paths, err := Find(s.dir, event)
+	if err != nil {
+		return t, err
+	}
+	return s.run(ctx, paths, event, t, editable)
+}
+
+// post runs each post-hook for event on t, reporting failures as warnings.
+func (s *Store) post(ctx context.Context, event string, t task.Task) {
+	paths, err := Find(s.dir, event)
+	if err != nil {
+		fmt.Fprintf(s.warnings, "Warning: %v\n", err)
+		return
+	}
+	for _, path := range paths {
+		if _, err := s.run(ctx, []string{path}, event, t, false); err != nil {
+			fmt.Fprintf(s.warnings, "Warning: %v\n", err)
+		}
+	}
+}
+
+// run runs the hooks at paths in turn. When editable, each hook sees the
+// task as left by the one before. A pre-hook exiting non-zero stops the
+// rest and is reported as a conflict, so the command fails like it does
+// for other refused changes.
+func (s *Store) run(ctx context.Context, paths []string, event string, t task.Task, editable bool) (task.Task, error) {
+	for _, path := range paths {
+		in, err := json.Marshal(exporter.ToJSON(t))
+		if err != nil {
+			return t, err
+		}
+		out, err := execute(ctx, path, event, in)
+		if err != nil {
+			return t, err
+		}
+		if editable && len(bytes.TrimSpace(out)) > 0 {
+			if t, err = apply(t, out); err != nil {
+				return t, fmt.Errorf("%s hook %s printed an invalid task: %w", event, filepath.Base(path), err)
+			}
+		}
+	}
+	return t, nil
+}
+
+// apply returns t with the name, project, status, tags and due date of the
+// JSON task out. Fields missing from out are left alone.
+func apply(t task.Task, out []byte) (task.Task, error) {
+	jt := exporter.ToJSON(t)
+	if err := json.Unmarshal(out, &jt); err != nil {
+		return t, err
+	}
+	var due time.Time
+	if jt.Due != "" {
+		var err error
+		if due, err = time.Parse(task.DueLayout, jt.Due); err != nil {
+			return t, fmt.Errorf("invalid due date %q: use YYYY-MM-DD", jt.Due)
+		}
+	}
+	t.Name, t.Project, t.Status, t.Tags, t.Due = jt.Name, jt.Project, jt.Status, jt.Tags, due
+	return t, nil
+}
+
+// execute runs the hook at path for event with stdin as its input and
+// returns its stdout. A non-zero exit is returned as a db.ErrConflict error
+// giving the hook's stderr as the reason.
+func execute(ctx context.Context, path, event string, stdin []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), EventEnv+"="+event)
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		reason := strings.TrimSpace(stderr.String())
+		if reason == "" {
+			reason = exitErr.Error()
+		}
+		verb := "failed"
+		if strings.HasPrefix(event, "pre-") {
+			verb = "refused the change"
+		}
+		return nil, db.Errorf(db.ErrConflict, "%s hook %s %s: %s", event, filepath.Base(path), verb, reason)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not run %s hook %s: %w", event, filepath.Base(path), err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,183 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/db/storetest"
+	"github.com/ashish0kumar/taskly/internal/memstore"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// writeHook creates an executable shell script named name in dir.
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newStore returns a memstore wrapped with the hooks in a new directory,
+// and that directory and the warnings written.
+func newStore(t *testing.T) (*Store, string, *bytes.Buffer) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in these tests are shell scripts")
+	}
+	dir := t.TempDir()
+	var warnings bytes.Buffer
+	return Wrap(memstore.New(), dir, &warnings), dir, &warnings
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store {
+		return Wrap(memstore.New(), t.TempDir(), &bytes.Buffer{})
+	})
+}
+
+func TestPreHookRejects(t *testing.T) {
+	s, dir, _ := newStore(t)
+	writeHook(t, dir, "pre-add-project", `grep -q '"project":""' && { echo "tasks need a project" >&2; exit 1; }; exit 0`)
+	ctx := context.Background()
+
+	_, err := s.InsertContext(ctx, "no project", "")
+	if !errors.Is(err, db.ErrConflict) || !strings.Contains(err.Error(), "tasks need a project") {
+		t.Fatalf("Insert without project = %v, want a conflict giving the hook's reason", err)
+	}
+	if tasks, _ := s.GetTasksContext(ctx); len(tasks) != 0 {
+		t.Fatalf("rejected task was added: %+v", tasks)
+	}
+	if _, err := s.InsertContext(ctx, "with project", "work"); err != nil {
+		t.Fatalf("Insert with project: %v", err)
+	}
+}
+
+func TestPreHookModifies(t *testing.T) {
+	s, dir, _ := newStore(t)
+	// Hooks run in name order, each seeing the last one's task.
+	writeHook(t, dir, "pre-add-1", `echo '{"project": "inbox"}'`)
+	writeHook(t, dir, "pre-add-2", `grep -q '"project":"inbox"' && echo '{"name": "triaged"}'`)
+	writeHook(t, dir, "pre-update", `echo '{"status": "done"}'`)
+	ctx := context.Background()
+
+	added, err := s.InsertContext(ctx, "raw", "")
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if added.Name != "triaged" || added.Project != "inbox" {
+		t.Fatalf("added %+v, want the hooks' name and project", added)
+	}
+
+	name := "renamed"
+	updated, err := s.UpdateContext(ctx, added.ID, db.Changes{Name: &name})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "renamed" || updated.Project != "inbox" || updated.Status != task.Done.String() {
+		t.Fatalf("updated %+v, want renamed, in inbox and done", updated)
+	}
+}
+
+func TestGuardSeesHookChanges(t *testing.T) {
+	s, dir, _ := newStore(t)
+	writeHook(t, dir, "pre-add", `echo '{"status": "in progress"}'`)
+	writeHook(t, dir, "pre-update", `echo '{"status": "done"}'`)
+	ctx := context.Background()
+	var seen []string
+	guard := func(ctx context.Context, before, after task.Task) error {
+		seen = append(seen, before.Status+">"+after.Status)
+		if after.Status == task.Done.String() {
+			return db.Errorf(db.ErrConflict, "no more done tasks")
+		}
+		return nil
+	}
+
+	added, err := Insert(ctx, s, task.Task{Name: "A"}, guard)
+	if err != nil || added.Status != task.InProgress.String() {
+		t.Fatalf("Insert = %+v, %v", added, err)
+	}
+	name := "B"
+	if _, err := Update(ctx, s, added.ID, db.Changes{Name: &name}, guard); !errors.Is(err, db.ErrConflict) {
+		t.Fatalf("Update the hook makes done = %v, want the guard's conflict", err)
+	}
+	if got, _ := s.GetTaskContext(ctx, added.ID); got.Name != "A" {
+		t.Errorf("refused update was saved: %+v", got)
+	}
+	if strings.Join(seen, ",") != ">in progress,in progress>done" {
+		t.Errorf("guard saw %v, want the statuses the hooks left", seen)
+	}
+
+	// Without hooks, the guard sees the change as asked for.
+	seen = nil
+	plain := memstore.New()
+	if _, err := Insert(ctx, plain, task.Task{Name: "C"}, guard); err != nil || strings.Join(seen, ",") != ">todo" {
+		t.Errorf("Insert into a plain store = %v; guard saw %v", err, seen)
+	}
+}
+
+func TestDeleteHooks(t *testing.T) {
+	s, dir, warnings := newStore(t)
+	log := filepath.Join(dir, "log")
+	writeHook(t, dir, "pre-delete", `grep -q '"name":"keep"' && exit 1; exit 0`)
+	writeHook(t, dir, "post-delete", `echo "$TASKLY_HOOK $(cat)" >> `+log)
+	writeHook(t, dir, "post-delete-broken", `echo "mail server down" >&2; exit 3`)
+	ctx := context.Background()
+
+	keep, _ := s.InsertContext(ctx, "keep", "")
+	drop, _ := s.InsertContext(ctx, "drop", "")
+
+	if err := s.DeleteContext(ctx, keep.ID); !errors.Is(err, db.ErrConflict) {
+		t.Fatalf("Delete refused by pre-delete = %v, want a conflict", err)
+	}
+	if err := s.DeleteContext(ctx, drop.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	got, _ := os.ReadFile(log)
+	if !strings.HasPrefix(string(got), "post-delete {") || !strings.Contains(string(got), `"name":"drop"`) {
+		t.Errorf("post-delete hook got %q, want the deleted task", got)
+	}
+	if !strings.Contains(warnings.String(), "mail server down") {
+		t.Errorf("warnings = %q, want the failing post-hook's reason", warnings.String())
+	}
+	if tasks, _ := s.GetTasksContext(ctx); len(tasks) != 1 || tasks[0].ID != keep.ID {
+		t.Errorf("tasks = %+v, want only the kept one", tasks)
+	}
+}
+
+func TestFind(t *testing.T) {
+	_, dir, _ := newStore(t)
+	writeHook(t, dir, "pre-add-b", "")
+	writeHook(t, dir, "pre-add-a", "")
+	writeHook(t, dir, "post-add", "")
+	writeHook(t, dir, "pre-add", "")
+	writeHook(t, dir, "pre-addition", "")
+	writeHook(t, dir, "pre-add~", "")
+	writeHook(t, dir, "pre-add-a.orig", "")
+	if err := os.WriteFile(filepath.Join(dir, "pre-add-disabled"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := Find(dir, PreAdd)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	var names []string
+	for _, p := range paths {
+		names = append(names, filepath.Base(p))
+	}
+	if strings.Join(names, ",") != "pre-add,pre-add-a,pre-add-b" {
+		t.Errorf("Find = %v, want the executable pre-add hooks in order, without backups", names)
+	}
+
+	if paths, err := Find(filepath.Join(dir, "missing"), PreAdd); err != nil || len(paths) != 0 {
+		t.Errorf("Find in a missing directory = %v, %v; want nothing", paths, err)
+	}
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// csvDateLayouts are tried in order when parsing dates in CSV files.
+var csvDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// csvColumns maps accepted header names (lower case) to task fields.
+var csvColumns = map[string]string{
+	"name":         "name",
+	"title":        "name",
+	"description":  "name",
+	"project":      "project",
+	"status":       "status",
+	"created":      "created",
+	"created at":   "created",
+	"updated":      "updated",
+	"completed":    "completed",
+	"completed at": "completed",
+	"tags":         "tags",
+	"labels":       "tags",
+	"due":          "due",
+	"due date":     "due",
+}
+
+// parseCSV reads a CSV file with a header row, such as one written by
+// `taskly export --format csv`. A name column is required; project, status,
+// created, updated, completed, tags and due are optional. Other columns are ignored.
+func parseCSV(r io.Reader) ([]task.Task, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed reading CSV header: %w", err)
+	}
+
+	index := map[string]int{}
+	for i, h := range header {
+		if field, ok := csvColumns[strings.ToLower(strings.TrimSpace(h))]; ok {
+			if _, seen := index[field]; !seen {
+				index[field] = i
+			}
+		}
+	}
+	if _, ok := index["name"]; !ok {
+		return nil, fmt.Errorf("CSV header has no name column")
+	}
+
+	var tasks []task.Task
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading CSV row %d: %w", row, err)
+		}
+		get := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		t := task.Task{Name: get("name"), Project: get("project"), Status: task.Todo.String(), Tags: task.ParseTags(get("tags"))}
+		if t.Name == "" {
+			continue
+		}
+		if s := get("status"); s != "" {
+			status, err := task.ParseStatus(strings.ToLower(s))
+			if err != nil {
+				return nil, fmt.Errorf("CSV row %d: %w", row, err)
+			}
+			t.Status = status.String()
+		}
+		if value := get("due"); value != "" {
+			due, err := parseCSVDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("CSV row %d: invalid due date %q", row, value)
+			}
+			t.Due = task.Day(due)
+		}
+		for field, dest := range map[string]*time.Time{"created": &t.Created, "updated": &t.Updated, "completed": &t.Completed} {
+			value := get(field)
+			if value == "" {
+				continue
+			}
+			ts, err := parseCSVDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("CSV row %d: invalid %s date %q", row, field, value)
+			}
+			*dest = ts
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// parseCSVDate parses a date in one of csvDateLayouts, using the local
+// timezone when the value has none.
+func parseCSVDate(value string) (time.Time, error) {
+	for _, layout := range csvDateLayouts {
+		if ts, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
+
+// parseTodoistCSV reads a Todoist project exported as CSV. Only rows of type
+// "task" are imported; Todoist exports open tasks only, so all are todo.
+// Labels, written as @label in the content, become tags.
+// Todoist does not include the project name, so use Options.Project.
+func parseTodoistCSV(r io.Reader) ([]task.Task, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed reading Todoist CSV header: %w", err)
+	}
+
+	typeCol, contentCol := -1, -1
+	for i, h := range header {
+		switch strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))) {
+		case "TYPE":
+			typeCol = i
+		case "CONTENT":
+			contentCol = i
+		}
+	}
+	if typeCol < 0 || contentCol < 0 {
+		return nil, fmt.Errorf("not a Todoist CSV export: missing TYPE or CONTENT column")
+	}
+
+	var tasks []task.Task
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading Todoist CSV row %d: %w", row, err)
+		}
+		if typeCol >= len(record) || contentCol >= len(record) || record[typeCol] != "task" {
+			continue
+		}
+		t := task.Task{Status: task.Todo.String()}
+		var words []string
+		for _, f := range strings.Fields(record[contentCol]) {
+			if len(f) > 1 && f[0] == '@' {
+				t.Tags = append(t.Tags, f[1:])
+				continue
+			}
+			words = append(words, f)
+		}
+		t.Name = strings.Join(words, " ")
+		if t.Name == "" {
+			continue
+		}
+		t.Tags = task.NormalizeTags(t.Tags)
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
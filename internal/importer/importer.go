@@ -0,0 +1,57 @@
+// Package importer reads tasks exported by other task managers.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Supported import formats.
+const (
+	FormatTaskwarrior = "taskwarrior-json"
+	FormatTodoTxt     = "todotxt"
+	FormatCSV         = "csv"
+	FormatTodoistCSV  = "todoist-csv"
+)
+
+// Formats returns the names accepted by Parse. Exported
+func Formats() []string {
+	return []string{FormatTaskwarrior, FormatTodoTxt, FormatCSV, FormatTodoistCSV}
+}
+
+// Options adjusts how parsed tasks are mapped. Exported
+type Options struct {
+	// Project is assigned to tasks that have no project of their own.
+	Project string
+}
+
+// Parse reads tasks in the given format. Returned tasks have no ID; fields
+// the source does not provide are left zero. Exported
+func Parse(format string, r io.Reader, opts Options) ([]task.Task, error) {
+	var tasks []task.Task
+	var err error
+	switch format {
+	case FormatTaskwarrior:
+		tasks, err = parseTaskwarrior(r)
+	case FormatTodoTxt:
+		tasks, err = parseTodoTxt(r)
+	case FormatCSV:
+		tasks, err = parseCSV(r)
+	case FormatTodoistCSV:
+		tasks, err = parseTodoistCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown import format %q (valid: %s)", format, strings.Join(Formats(), ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].Project == "" {
+			tasks[i].Project = opts.Project
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// parseTest is one input for Parse and the tasks, or the error, it gives.
+type parseTest struct {
+	name    string
+	input   string
+	want    []task.Task
+	wantErr string // Substring of the expected error; empty for success
+}
+
+// runParseTests runs tests against Parse in format.
+func runParseTests(t *testing.T, format string, tests []parseTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(format, strings.NewReader(tt.input), Options{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse gave %d tasks, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range tt.want {
+				if !sameTask(got[i], tt.want[i]) {
+					t.Errorf("task %d = %+v, want %+v", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// sameTask compares the fields importers fill in, comparing times as instants.
+func sameTask(a, b task.Task) bool {
+	return a.Name == b.Name && a.Project == b.Project && a.Status == b.Status &&
+		strings.Join(a.Tags, ",") == strings.Join(b.Tags, ",") && a.Due.Equal(b.Due) &&
+		a.Created.Equal(b.Created) && a.Updated.Equal(b.Updated) && a.Completed.Equal(b.Completed)
+}
+
+// date returns midnight on the given day in loc.
+func date(y int, m time.Month, d int, loc *time.Location) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	runParseTests(t, FormatTodoTxt, []parseTest{
+		{
+			name:  "fields",
+			input: "(A) 2026-10-01 Call mom +family @phone @Home due:2026-10-31 rec:1w\n\n",
+			want:  []task.Task{{Name: "(A) Call mom rec:1w", Project: "family", Status: "todo", Tags: []string{"home", "phone"}, Due: date(2026, 10, 31, time.UTC), Created: date(2026, 10, 1, time.Local)}},
+		},
+		{
+			name:  "done with dates",
+			input: "x 2026-10-05 2026-10-01 Pay bills",
+			want:  []task.Task{{Name: "Pay bills", Status: "done", Completed: date(2026, 10, 5, time.Local), Created: date(2026, 10, 1, time.Local)}},
+		},
+		{
+			name:  "not a date",
+			input: "2026-13-45 Odd start",
+			want:  []task.Task{{Name: "2026-13-45 Odd start", Status: "todo"}},
+		},
+		{name: "bad due date", input: "Pay rent\nRenew passport due:next-week", wantErr: "line 2: invalid due date"},
+		{name: "only tags", input: "+work @office", wantErr: "line 1: task has no description"},
+	})
+}
+
+func TestParseCSV(t *testing.T) {
+	runParseTests(t, FormatCSV, []parseTest{
+		{
+			name: "export columns",
+			input: "id,name,project,status,created,updated,completed,tags,due\n" +
+				"1,\"Ship it, finally\",v2,done,2026-10-01T09:00:00Z,2026-10-02T09:00:00Z,2026-10-02T09:00:00Z,\"Release,urgent\",2026-10-03\n",
+			want: []task.Task{{
+				Name: "Ship it, finally", Project: "v2", Status: "done", Tags: []string{"release", "urgent"}, Due: date(2026, 10, 3, time.UTC),
+				Created: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), Updated: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC), Completed: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name:  "aliases and local dates",
+			input: "Title,Created At,Due Date,Labels,Extra\nWater plants,2026-10-01 08:30,2026-10-04,garden,ignored\n,,,,\n",
+			want:  []task.Task{{Name: "Water plants", Status: "todo", Tags: []string{"garden"}, Due: date(2026, 10, 4, time.UTC), Created: time.Date(2026, 10, 1, 8, 30, 0, 0, time.Local)}},
+		},
+		{name: "empty", input: ""},
+		{name: "no name column", input: "project,status\nv2,todo\n", wantErr: "no name column"},
+		{name: "bad status", input: "name,status\nA,blocked\n", wantErr: "CSV row 2"},
+		{name: "bad date", input: "name,created\nA,yesterday\n", wantErr: `invalid created date "yesterday"`},
+		{name: "bad due date", input: "name,due\nA,31/10/2026\n", wantErr: "invalid due date"},
+		{name: "bad quoting", input: "name\n\"unterminated\n", wantErr: "row 2"},
+	})
+}
+
+func TestParseTodoistCSV(t *testing.T) {
+	runParseTests(t, FormatTodoistCSV, []parseTest{
+		{
+			name:  "tasks only",
+			input: "\ufeffTYPE,CONTENT,PRIORITY\nsection,Backlog,\ntask,Draft post @writing,4\nnote,Remember this,\ntask,@only-label,1\n",
+			want:  []task.Task{{Name: "Draft post", Status: "todo", Tags: []string{"writing"}}},
+		},
+		{name: "not todoist", input: "name,project\nA,B\n", wantErr: "missing TYPE or CONTENT"},
+	})
+}
+
+func TestParseTaskwarrior(t *testing.T) {
+	entry := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	runParseTests(t, FormatTaskwarrior, []parseTest{
+		{
+			name: "array",
+			input: `[{"description":"Write docs","project":"v2","status":"pending","start":"20261002T090000Z","entry":"20261001T090000Z","tags":["Docs"],"due":"20261031T120000Z"},
+				{"description":"Gone","status":"deleted"},
+				{"description":"Every week","status":"recurring"},
+				{"description":"Ship","status":"completed","entry":"20261001T090000Z","end":"20261003T090000Z"}]`,
+			want: []task.Task{
+				{Name: "Write docs", Project: "v2", Status: "in progress", Tags: []string{"docs"}, Due: date(2026, 10, 31, time.UTC), Created: entry},
+				{Name: "Ship", Status: "done", Created: entry, Completed: time.Date(2026, 10, 3, 9, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "one object per line",
+			input: "{\"description\":\"A\",\"status\":\"waiting\",\"end\":\"20261003T090000Z\"},\n\n{\"description\":\"B\"}\n",
+			want:  []task.Task{{Name: "A", Status: "todo"}, {Name: "B", Status: "todo"}},
+		},
+		{name: "bad array", input: `[{"description":"A"`, wantErr: "invalid taskwarrior JSON"},
+		{name: "bad line", input: "{\"description\":\"A\"}\nnot json\n", wantErr: "line 2"},
+		{name: "unknown status", input: `[{"description":"A","status":"paused"}]`, wantErr: `unknown status "paused"`},
+		{name: "bad date", input: `[{"description":"A","entry":"2026-10-01"}]`, wantErr: `invalid date "2026-10-01"`},
+		{name: "bad due date", input: `[{"description":"A","due":"tomorrow"}]`, wantErr: `invalid due date "tomorrow"`},
+	})
+}
+
+func TestParseOptions(t *testing.T) {
+	tasks, err := Parse(FormatTodoTxt, strings.NewReader("A +own\nB\n"), Options{Project: "inbox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[0].Project != "own" || tasks[1].Project != "inbox" {
+		t.Errorf("projects = %q, %q; want own, inbox", tasks[0].Project, tasks[1].Project)
+	}
+	if _, err := Parse("xml", strings.NewReader(""), Options{}); err == nil || !strings.Contains(err.Error(), "unknown import format") {
+		t.Errorf("Parse(xml) = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// taskwarriorLayout is the UTC timestamp format used by `task export`.
+const taskwarriorLayout = "20060102T150405Z"
+
+// taskwarriorTask holds the fields of a Taskwarrior export record we map.
+type taskwarriorTask struct {
+	Description string   `json:"description"`
+	Project     string   `json:"project"`
+	Status      string   `json:"status"`
+	Entry       string   `json:"entry"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Modified    string   `json:"modified"`
+	Tags        []string `json:"tags"`
+	Due         string   `json:"due"`
+}
+
+// parseTaskwarrior reads the output of `task export`, either a JSON array or
+// one JSON object per line as written by older Taskwarrior versions.
+// Deleted tasks and recurrence templates are skipped.
+func parseTaskwarrior(r io.Reader) ([]task.Task, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading taskwarrior export: %w", err)
+	}
+
+	var records []taskwarriorTask
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("invalid taskwarrior JSON: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := bytes.TrimRight(bytes.TrimSpace(scanner.Bytes()), ",")
+			if len(text) == 0 {
+				continue
+			}
+			var rec taskwarriorTask
+			if err := json.Unmarshal(text, &rec); err != nil {
+				return nil, fmt.Errorf("invalid taskwarrior JSON on line %d: %w", line, err)
+			}
+			records = append(records, rec)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed reading taskwarrior export: %w", err)
+		}
+	}
+
+	var tasks []task.Task
+	for i, rec := range records {
+		t := task.Task{Name: rec.Description, Project: rec.Project, Tags: task.NormalizeTags(rec.Tags)}
+		switch rec.Status {
+		case "deleted", "recurring":
+			continue
+		case "completed":
+			t.Status = task.Done.String()
+		case "pending", "waiting", "":
+			t.Status = task.Todo.String()
+			if rec.Start != "" {
+				t.Status = task.InProgress.String()
+			}
+		default:
+			return nil, fmt.Errorf("taskwarrior record %d: unknown status %q", i+1, rec.Status)
+		}
+
+		for _, field := range []struct {
+			value string
+			dest  *time.Time
+		}{{rec.Entry, &t.Created}, {rec.Modified, &t.Updated}, {rec.End, &t.Completed}} {
+			if field.value == "" {
+				continue
+			}
+			ts, err := time.Parse(taskwarriorLayout, field.value)
+			if err != nil {
+				return nil, fmt.Errorf("taskwarrior record %d: invalid date %q", i+1, field.value)
+			}
+			*field.dest = ts
+		}
+		if t.Status != task.Done.String() {
+			t.Completed = time.Time{}
+		}
+		if rec.Due != "" {
+			due, err := time.Parse(taskwarriorLayout, rec.Due)
+			if err != nil {
+				return nil, fmt.Errorf("taskwarrior record %d: invalid due date %q", i+1, rec.Due)
+			}
+			// Taskwarrior stores due times in UTC; keep the local day.
+			t.Due = task.Day(due.Local())
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// todoTxtDate is the date format used in todo.txt files.
+const todoTxtDate = "2006-01-02"
+
+// parseTodoTxt reads a todo.txt file. Completed lines ("x ...") become done
+// tasks, the first +project tag becomes the project, @contexts become tags
+// and a due:YYYY-MM-DD tag the due date; all are removed from the name.
+// Priorities and other key:value tags are kept in the name.
+func parseTodoTxt(r io.Reader) ([]task.Task, error) {
+	var tasks []task.Task
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		t, err := parseTodoTxtLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("todo.txt line %d: %w", line, err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading todo.txt: %w", err)
+	}
+	return tasks, nil
+}
+
+// parseTodoTxtLine maps one non-empty todo.txt line onto a task.
+func parseTodoTxtLine(text string) (task.Task, error) {
+	t := task.Task{Status: task.Todo.String()}
+	fields := strings.Fields(text)
+
+	if len(fields) > 0 && fields[0] == "x" {
+		t.Status = task.Done.String()
+		fields = fields[1:]
+		// A completed task may carry a completion date and then a creation date.
+		if len(fields) > 0 {
+			if d, err := time.ParseInLocation(todoTxtDate, fields[0], time.Local); err == nil {
+				t.Completed = d
+				fields = fields[1:]
+			}
+		}
+	}
+
+	// Keep a priority such as "(A)" at the front of the name; taskly has no
+	// priorities of its own.
+	var words []string
+	if len(fields) > 0 && len(fields[0]) == 3 && fields[0][0] == '(' && fields[0][2] == ')' {
+		words = append(words, fields[0])
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 {
+		if d, err := time.ParseInLocation(todoTxtDate, fields[0], time.Local); err == nil {
+			t.Created = d
+			fields = fields[1:]
+		}
+	}
+
+	for _, f := range fields {
+		if t.Project == "" && len(f) > 1 && f[0] == '+' {
+			t.Project = f[1:]
+			continue
+		}
+		if len(f) > 1 && f[0] == '@' {
+			t.Tags = append(t.Tags, f[1:])
+			continue
+		}
+		if value, ok := strings.CutPrefix(f, "due:"); ok {
+			due, err := time.Parse(todoTxtDate, value)
+			if err != nil {
+				return task.Task{}, fmt.Errorf("invalid due date %q: use YYYY-MM-DD", value)
+			}
+			t.Due = due
+			continue
+		}
+		words = append(words, f)
+	}
+	t.Tags = task.NormalizeTags(t.Tags)
+	t.Name = strings.Join(words, " ")
+	if t.Name == "" {
+		return task.Task{}, fmt.Errorf("task has no description")
+	}
+	return t, nil
+}
@@ -0,0 +1,337 @@
+// Package memstore implements db.Store in memory. Nothing is persisted, which
+// makes it suitable for tests and for embedding taskly without a data file.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Store keeps tasks in a map. It is safe for concurrent use. Exported
+type Store struct {
+	mu      sync.Mutex
+	tasks   map[uint]task.Task
+	lastID  uint
+	version int64 // Incremented on every change, for watchers
+}
+
+// Store must satisfy db.Store.
+var _ db.Store = (*Store)(nil)
+
+// New returns an empty store. Exported
+func New() *Store {
+	return &Store{tasks: map[uint]task.Task{}}
+}
+
+// Close releases the store. Its tasks are kept, so it can still be read.
+func (s *Store) Close() error {
+	return nil
+}
+
+// all returns every task, in no particular order. The caller holds s.mu.
+func (s *Store) all() []task.Task {
+	tasks := make([]task.Task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	return tasks
+}
+
+// add stores t under a new ID. The caller holds s.mu.
+func (s *Store) add(t task.Task) task.Task {
+	s.lastID++
+	t.ID = s.lastID
+	s.tasks[t.ID] = t
+	s.version++
+	return t
+}
+
+// InsertContext adds a new task.
+func (s *Store) InsertContext(ctx context.Context, name, project string) (task.Task, error) {
+	return s.InsertTaskContext(ctx, task.Task{Name: name, Project: project})
+}
+
+// InsertTaskContext adds a new task with the name, project, status and tags
+// of t, which starts as todo if t has no status.
+func (s *Store) InsertTaskContext(ctx context.Context, t task.Task) (task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return task.Task{}, err
+	}
+	t, err := db.PrepareInsert(t, time.Now().UTC())
+	if err != nil {
+		return task.Task{}, fmt.Errorf("insert failed: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.Position = db.NextPosition(s.all())
+	return s.add(t), nil
+}
+
+// RestoreContext re-inserts a previously deleted task, keeping its ID and timestamps.
+func (s *Store) RestoreContext(ctx context.Context, t task.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := db.ValidateTask(t); err != nil {
+		return fmt.Errorf("restore failed for id %d: %w", t.ID, err)
+	}
+	t.Tags = task.NormalizeTags(t.Tags)
+	t.Due = task.Day(t.Due)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tasks[t.ID]; ok {
+		return db.Errorf(db.ErrConflict, "restore failed for id %d: task already exists", t.ID)
+	}
+	s.tasks[t.ID] = t
+	if t.ID > s.lastID {
+		s.lastID = t.ID
+	}
+	s.version++
+	return nil
+}
+
+// UpdateContext modifies an existing task.
+func (s *Store) UpdateContext(ctx context.Context, id uint, ch db.Changes) (task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return task.Task{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return task.Task{}, db.Errorf(db.ErrNotFound, "cannot update task %d: task with ID %d not found", id, id)
+	}
+	if err := db.CheckUnchanged(t, ch); err != nil {
+		return task.Task{}, err
+	}
+	if !db.ApplyUpdate(&t, ch, time.Now().UTC()) {
+		return t, nil
+	}
+	if err := db.ValidateTask(t); err != nil {
+		return task.Task{}, fmt.Errorf("update failed for id %d: %w", id, err)
+	}
+	s.tasks[id] = t
+	s.version++
+	return t, nil
+}
+
+// DeleteContext removes a task by ID.
+func (s *Store) DeleteContext(ctx context.Context, id uint) error {
+	return s.DeleteIfUpdatedContext(ctx, id, time.Time{})
+}
+
+// DeleteIfUpdatedContext removes a task by ID if it was last updated at
+// updated, or whenever updated is zero.
+func (s *Store) DeleteIfUpdatedContext(ctx context.Context, id uint, updated time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return db.Errorf(db.ErrNotFound, "task with ID %d not found for deletion", id)
+	}
+	if err := db.CheckUnchanged(t, db.Changes{IfUpdated: updated}); err != nil {
+		return err
+	}
+	delete(s.tasks, id)
+	s.version++
+	return nil
+}
+
+// GetTaskContext retrieves a single task by ID.
+func (s *Store) GetTaskContext(ctx context.Context, id uint) (task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return task.Task{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return task.Task{}, db.Errorf(db.ErrNotFound, "task with ID %d not found", id)
+	}
+	return t, nil
+}
+
+// GetTasksContext retrieves all tasks, ordered by manual rank within status.
+func (s *Store) GetTasksContext(ctx context.Context) ([]task.Task, error) {
+	return s.ListTasksContext(ctx, db.ListOptions{})
+}
+
+// GetTasksByStatusContext retrieves tasks filtered by status, oldest first.
+func (s *Store) GetTasksByStatusContext(ctx context.Context, status string) ([]task.Task, error) {
+	tasks, err := s.ListTasksContext(ctx, db.ListOptions{Sort: []db.SortKey{{Field: "created"}}})
+	if err != nil {
+		return nil, err
+	}
+	filtered := []task.Task{}
+	for _, t := range tasks {
+		if t.Status == status {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// ListTasksContext retrieves tasks according to the given options.
+func (s *Store) ListTasksContext(ctx context.Context, opts db.ListOptions) ([]task.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return opts.Apply(s.all())
+}
+
+// CountTasksByStatusContext returns the number of tasks in each status.
+func (s *Store) CountTasksByStatusContext(ctx context.Context) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return db.CountByStatus(s.all()), nil
+}
+
+// GetProjectSummariesContext aggregates tasks per project, ordered by project name.
+func (s *Store) GetProjectSummariesContext(ctx context.Context) ([]db.ProjectSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return db.SummarizeProjects(s.all()), nil
+}
+
+// MoveToTopContext ranks a task before every other task.
+func (s *Store) MoveToTopContext(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, 0, db.PlaceTop)
+}
+
+// MoveToBottomContext ranks a task after every other task.
+func (s *Store) MoveToBottomContext(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, 0, db.PlaceBottom)
+}
+
+// MoveBeforeContext ranks a task immediately before another one.
+func (s *Store) MoveBeforeContext(ctx context.Context, id, otherID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, otherID, db.PlaceBefore)
+}
+
+// MoveAfterContext ranks a task immediately after another one.
+func (s *Store) MoveAfterContext(ctx context.Context, id, otherID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.rerank(id, otherID, db.PlaceAfter)
+}
+
+// rerank moves a task and stores every changed position.
+func (s *Store) rerank(id, otherID uint, place db.Placement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := s.all()
+	if err := db.Rerank(tasks, id, otherID, place); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if t.ID == id {
+			t.Updated = time.Now().UTC()
+		}
+		s.tasks[t.ID] = t
+	}
+	s.version++
+	return nil
+}
+
+// ImportTasksContext adds tasks with the same defaults and duplicate detection as
+// the SQLite store, either all of them or none.
+func (s *Store) ImportTasksContext(ctx context.Context, tasks []task.Task, opts db.ImportOptions) (db.ImportResult, error) {
+	var result db.ImportResult
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, t := range s.tasks {
+		seen[db.DuplicateKey(t.Name, t.Project)] = true
+	}
+	now := time.Now().UTC()
+	var pending []task.Task
+	for i, t := range tasks {
+		t, err := db.PrepareImport(t, i, now)
+		if err != nil {
+			return result, err
+		}
+		key := db.DuplicateKey(t.Name, t.Project)
+		if seen[key] && !opts.AllowDuplicates {
+			result.Duplicates = append(result.Duplicates, t)
+			continue
+		}
+		seen[key] = true
+		pending = append(pending, t)
+	}
+
+	lastID, position := s.lastID, db.NextPosition(s.all())
+	for _, t := range pending {
+		lastID++
+		t.ID, t.Position = lastID, position
+		position = db.NextPosition([]task.Task{t})
+		result.Imported = append(result.Imported, t)
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	for _, t := range result.Imported {
+		s.tasks[t.ID] = t
+	}
+	s.lastID = lastID
+	s.version++
+	return result, nil
+}
+
+// NewWatcher returns a watcher reporting changes made through this store.
+func (s *Store) NewWatcher() (db.ChangeWatcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &watcher{store: s, last: s.version}, nil
+}
+
+// watcher compares the store's version counter between calls.
+type watcher struct {
+	store *Store
+	last  int64
+}
+
+// Changed reports whether the store was modified since the last call.
+func (w *watcher) Changed() (bool, error) {
+	w.store.mu.Lock()
+	defer w.store.mu.Unlock()
+	changed := w.store.version != w.last
+	w.last = w.store.version
+	return changed, nil
+}
+
+// Close releases the watcher.
+func (w *watcher) Close() error {
+	return nil
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/exporter"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+const (
+	// DefaultPollInterval is how often Watch checks the store for changes
+	// made outside the server when Options.PollInterval is zero. Exported
+	DefaultPollInterval = time.Second
+
+	// feedHistory is how many events are kept for clients resuming a
+	// stream with Last-Event-ID.
+	feedHistory = 1024
+
+	// keepAliveInterval is how often an idle event stream sends a comment,
+	// so proxies and clients can tell it is still alive.
+	keepAliveInterval = 15 * time.Second
+)
+
+// event is one entry of the change feed.
+type event struct {
+	Seq  uint64            `json:"seq"`
+	Type task.ChangeType   `json:"type"`
+	Task exporter.JSONTask `json:"task"`
+}
+
+// feed numbers changes to the store and keeps the most recent ones for
+// streaming to clients.
+type feed struct {
+	// epoch tells this run's events from those of earlier runs, whose
+	// sequence numbers also start at 1.
+	epoch int64
+
+	mu     sync.Mutex
+	seq    uint64        // Sequence number of the latest event
+	events []event       // The latest events, oldest first
+	wake   chan struct{} // Closed and replaced when events are added
+	closed bool
+
+	poke chan struct{} // Asks Watch to check for changes now
+}
+
+func newFeed() *feed {
+	return &feed{epoch: time.Now().UnixNano(), wake: make(chan struct{}), poke: make(chan struct{}, 1)}
+}
+
+// id returns the event ID for sequence number seq: "<epoch>-<seq>".
+func (f *feed) id(seq uint64) string {
+	return fmt.Sprintf("%d-%d", f.epoch, seq)
+}
+
+// parseEventID splits an event ID made by feed.id into its epoch and
+// sequence number.
+func parseEventID(id string) (epoch int64, seq uint64, err error) {
+	e, s, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid event ID %q", id)
+	}
+	if epoch, err = strconv.ParseInt(e, 10, 64); err == nil {
+		seq, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid event ID %q", id)
+	}
+	return epoch, seq, nil
+}
+
+// publish numbers changes and adds them to the feed.
+func (f *feed) publish(changes []task.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range changes {
+		f.seq++
+		f.events = append(f.events, event{Seq: f.seq, Type: c.Type, Task: exporter.ToJSON(c.Task)})
+	}
+	if extra := len(f.events) - feedHistory; extra > 0 {
+		f.events = append(f.events[:0:0], f.events[extra:]...)
+	}
+	close(f.wake)
+	f.wake = make(chan struct{})
+}
+
+// since returns the events after sequence number seq, and a channel closed
+// when more arrive. ok is false if the events after seq are no longer
+// known, because they were dropped from the history or seq is ahead of the
+// feed.
+func (f *feed) since(seq uint64) (events []event, wake <-chan struct{}, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if seq > f.seq || (len(f.events) > 0 && seq+1 < f.events[0].Seq) {
+		return nil, f.wake, false
+	}
+	start := len(f.events) - int(f.seq-seq)
+	return f.events[start:], f.wake, true
+}
+
+// latest returns the sequence number of the latest event.
+func (f *feed) latest() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.seq
+}
+
+// close wakes all streams for good, ending them.
+func (f *feed) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.wake)
+	}
+}
+
+// isClosed reports whether close was called.
+func (f *feed) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// changed asks Watch to look for changes without waiting for the next poll,
+// after the server itself changed the store.
+func (f *feed) changed() {
+	select {
+	case f.poke <- struct{}{}:
+	default:
+	}
+}
+
+// Watch feeds /api/events with the changes made to the store, whether
+// through the server or by other processes such as the taskly command,
+// until ctx is done. Changes made outside the server are noticed within
+// Options.PollInterval. Open event streams end when Watch returns.
+//
+// Watch returns ctx.Err() once ctx is done, or an error if the store cannot
+// be watched or read at first. Later errors reading the store are retried
+// at the next poll. Exported
+func (s *Server) Watch(ctx context.Context) error {
+	defer s.feed.close()
+
+	watcher, err := s.store.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	prev, err := s.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	interval := s.opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	stale := false // Set when a snapshot failed and must be taken again
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.feed.poke:
+		case <-ticker.C:
+			// Watchers only see other processes' writes to the SQLite
+			// database; the server's own writes poke the feed instead.
+			changed, err := watcher.Changed()
+			if err != nil || !(changed || stale) {
+				continue
+			}
+		}
+
+		next, err := s.snapshot(ctx)
+		if stale = err != nil; stale {
+			continue
+		}
+		s.feed.publish(task.Diff(prev, next))
+		prev = next
+	}
+}
+
+// snapshot returns every task keyed by ID.
+func (s *Server) snapshot(ctx context.Context) (map[uint]task.Task, error) {
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+	tasks, err := s.store.GetTasksContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return task.Snapshot(tasks), nil
+}
+
+// handleEvents streams the change feed as server-sent events. Each event
+// has the change type as its name, the feed's epoch and its sequence number
+// as its ID and the event as JSON data. A client reconnecting with
+// Last-Event-ID receives the events it missed, or a reset event if they are
+// no longer known, for example because the ID is from an earlier run of the
+// server, and it must reload the tasks. Without Last-Event-ID the stream
+// starts with a ready event carrying the current sequence number.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	seq := s.feed.latest()
+	resume, known := false, true
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		epoch, last, err := parseEventID(id)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid Last-Event-ID %q", id))
+			return
+		}
+		seq, resume, known = last, true, epoch == s.feed.epoch
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if !resume {
+		fmt.Fprintf(w, "id: %s\nevent: ready\ndata: {\"seq\":%d}\n\n", s.feed.id(seq), seq)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		events, wake, ok := s.feed.since(seq)
+		if !ok || !known {
+			// Sequence numbers from another run name other events.
+			events, known = nil, true
+			seq = s.feed.latest()
+			fmt.Fprintf(w, "id: %s\nevent: reset\ndata: {\"seq\":%d}\n\n", s.feed.id(seq), seq)
+		}
+		for _, e := range events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", s.feed.id(e.Seq), e.Type, data)
+			seq = e.Seq
+		}
+		flusher.Flush()
+		if s.feed.isClosed() {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-wake:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/memstore"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// sse is one server-sent event.
+type sse struct {
+	id, name, data string
+}
+
+// stream opens the event stream of srv, resuming after lastID if it is not
+// empty, and returns a function reading the next event.
+func stream(t *testing.T, srv *httptest.Server, lastID string) func() sse {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != "text/event-stream" {
+		t.Fatalf("GET /api/events: %d, %s", resp.StatusCode, ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return func() sse {
+		t.Helper()
+		var e sse
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatal("event stream ended")
+				}
+				switch {
+				case line == "" && e.id != "":
+					return e
+				case strings.HasPrefix(line, "id: "):
+					e.id = strings.TrimPrefix(line, "id: ")
+				case strings.HasPrefix(line, "event: "):
+					e.name = strings.TrimPrefix(line, "event: ")
+				case strings.HasPrefix(line, "data: "):
+					e.data = strings.TrimPrefix(line, "data: ")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for an event")
+			}
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	store := memstore.New()
+	if _, err := store.InsertContext(context.Background(), "Existing", ""); err != nil {
+		t.Fatal(err)
+	}
+	api := New(store, Options{PollInterval: 10 * time.Millisecond})
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	watching := make(chan error, 1)
+	go func() { watching <- api.Watch(ctx) }()
+	srv := httptest.NewServer(api.Handler())
+	defer srv.Close()
+
+	next := stream(t, srv, "")
+	if e := next(); e.name != "ready" || e.id != api.feed.id(0) {
+		t.Fatalf("first event = %+v, want ready with ID %s", e, api.feed.id(0))
+	}
+
+	// The API's own change is published right away; one made directly to
+	// the store is found by polling.
+	change := []func(){
+		func() { do(t, api.Handler(), http.MethodPost, "/api/tasks", `{"name":"Through the API"}`) },
+		func() {
+			if _, err := store.UpdateContext(context.Background(), 1, db.Changes{Status: strPtr(task.Done.String())}); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	var got []event
+	for _, fn := range change {
+		fn()
+		e := next()
+		var ev event
+		if err := json.Unmarshal([]byte(e.data), &ev); err != nil {
+			t.Fatalf("event data %q: %v", e.data, err)
+		}
+		if e.name != string(ev.Type) || e.id != api.feed.id(ev.Seq) {
+			t.Errorf("event %+v does not match its data", e)
+		}
+		got = append(got, ev)
+	}
+	if got[0].Seq != 1 || got[0].Type != task.Created || got[0].Task.Name != "Through the API" {
+		t.Errorf("first change = %+v", got[0])
+	}
+	if got[1].Seq != 2 || got[1].Type != task.Updated || got[1].Task.Status != "done" {
+		t.Errorf("second change = %+v", got[1])
+	}
+
+	// A client that saw event 1 gets event 2 again on reconnecting, and one
+	// from an unknown future is told to start over.
+	if e := stream(t, srv, api.feed.id(1))(); e.id != api.feed.id(2) || e.name != "updated" {
+		t.Errorf("resumed after 1: %+v", e)
+	}
+	if e := stream(t, srv, api.feed.id(99))(); e.id != api.feed.id(2) || e.name != "reset" {
+		t.Errorf("resumed after 99: %+v", e)
+	}
+	if resp := do(t, api.Handler(), http.MethodGet, "/api/events", "", "Last-Event-ID", "2"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Last-Event-ID without an epoch: %d, want 400", resp.StatusCode)
+	}
+
+	stop()
+	if err := <-watching; err != context.Canceled {
+		t.Errorf("Watch returned %v, want context.Canceled", err)
+	}
+}
+
+func TestEventsAfterRestart(t *testing.T) {
+	store := memstore.New()
+	created := []task.Change{{Type: task.Created, Task: task.Task{ID: 1, Name: "Before"}}}
+	first := New(store, Options{})
+	srv := httptest.NewServer(first.Handler())
+	t.Cleanup(srv.Close) // After the streams' cleanup ends them
+	next := stream(t, srv, "")
+	next() // ready
+	first.feed.publish(created)
+	seen := next()
+
+	// The restarted server numbers its events from 1 again, so the
+	// client's ID names another event and it must start over.
+	time.Sleep(time.Millisecond)
+	second := New(store, Options{})
+	srv2 := httptest.NewServer(second.Handler())
+	t.Cleanup(srv2.Close)
+	second.feed.publish(created)
+	second.feed.publish(created)
+	if e := stream(t, srv2, seen.id)(); e.name != "reset" || e.id != second.feed.id(2) {
+		t.Errorf("resumed after %s from the first run: %+v, want reset at %s", seen.id, e, second.feed.id(2))
+	}
+}
+
+func TestFeedHistory(t *testing.T) {
+	f := newFeed()
+	for i := 0; i < feedHistory+10; i++ {
+		f.publish([]task.Change{{Type: task.Created, Task: task.Task{ID: uint(i + 1)}}})
+	}
+	if events, _, ok := f.since(5); ok {
+		t.Errorf("since(5) = %d events, want them to be forgotten", len(events))
+	}
+	events, _, ok := f.since(feedHistory + 8)
+	if !ok || len(events) != 2 || events[0].Seq != feedHistory+9 {
+		t.Errorf("since(%d) = %+v, %v", feedHistory+8, events, ok)
+	}
+	if _, _, ok := f.since(10); !ok {
+		t.Error("since(10) forgot event 11, the oldest kept")
+	}
+}
+
+func strPtr(s string) *string { return &s }
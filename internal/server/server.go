@@ -0,0 +1,228 @@
+// Package server implements taskly's HTTP API, which serves tasks from a
+// db.Store as JSON for dashboards and scripts.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+// maxBodyBytes caps the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// openAPIDoc describes the API in OpenAPI 3 format.
+//
+//go:embed openapi.json
+var openAPIDoc []byte
+
+// webFiles holds the browser view served at the root, which works entirely
+// through the API.
+//
+//go:embed web
+var webFiles embed.FS
+
+// Options configures a Server. Exported
+type Options struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>"
+	// with every API request. Without one, only requests addressed to a
+	// loopback host are answered.
+	Token string
+
+	// Timeout bounds the store operations of each request; zero means no
+	// limit.
+	Timeout time.Duration
+
+	// WIPLimits caps how many tasks may be in each status, as in the
+	// config file. Changes that would exceed a limit are refused.
+	WIPLimits map[string]int
+	// PollInterval is how often Watch checks for changes made outside the
+	// server; zero means DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// Server serves the HTTP API for one store. Exported
+type Server struct {
+	store db.Store
+	opts  Options
+	feed  *feed
+
+	// mu serializes changes so a WIP limit or If-Match check and the write
+	// it guards cannot interleave with another request's. Updates and
+	// deletions are also conditional in the store, against changes from
+	// other processes.
+	mu sync.Mutex
+}
+
+// New returns a Server for store. Exported
+func New(store db.Store, opts Options) *Server {
+	return &Server{store: store, opts: opts, feed: newFeed()}
+}
+
+// Handler returns the HTTP handler serving the API under /api/ and the web
+// view at /. Exported
+func (s *Server) Handler() http.Handler {
+	web, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err) // The directory is embedded above
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(web)))
+	mux.HandleFunc("/api/openapi.json", s.handleOpenAPI)
+	mux.Handle("/api/tasks", s.api(s.handleTasks))
+	mux.Handle("/api/tasks/", s.api(s.handleTask))
+	mux.Handle("/api/events", s.authenticated(s.handleEvents))
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	})
+	return mux
+}
+
+// api wraps an API handler with authentication, the request timeout and a
+// body size limit.
+func (s *Server) api(h http.HandlerFunc) http.Handler {
+	return s.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := s.context(r.Context())
+		defer cancel()
+		r = r.WithContext(ctx)
+		if r.ContentLength != 0 && !isJSON(r.Header.Get("Content-Type")) {
+			// Insisting on JSON also stops other web pages from changing
+			// tasks with plain form posts, which browsers send anywhere.
+			writeError(w, http.StatusUnsupportedMediaType, "request body must be application/json")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		h(w, r)
+	})
+}
+
+// authenticated wraps a handler so it only runs for requests carrying the
+// configured bearer token.
+func (s *Server) authenticated(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.Token == "" && !isLoopbackHost(r.Host) {
+			// Without a token, a web page whose domain was pointed at
+			// 127.0.0.1 (DNS rebinding) could otherwise use the API.
+			writeError(w, http.StatusForbidden, "requests must be addressed to localhost unless the server has a token")
+			return
+		}
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="taskly"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		h(w, r)
+	})
+}
+
+// context bounds a store operation by the configured timeout.
+func (s *Server) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.opts.Timeout > 0 {
+		return context.WithTimeout(ctx, s.opts.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// authorized reports whether r carries the configured bearer token.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.opts.Token == "" {
+		return true
+	}
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(s.opts.Token)) == 1
+}
+
+// isLoopbackHost reports whether a Host header names this machine:
+// localhost, a name under .localhost or a loopback address.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// isJSON reports whether a Content-Type header value names JSON.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// handleOpenAPI serves the OpenAPI document. It needs no token, as it holds
+// no task data.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, http.MethodGet, http.MethodHead)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDoc)
+}
+
+// apiError is the body of every error response.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, apiError{Error: msg})
+}
+
+// writeStoreError writes the response for an error from the store, choosing
+// the status code from its kind.
+func writeStoreError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, db.ErrInvalidInput):
+		status = http.StatusBadRequest
+	case errors.Is(err, db.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, db.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, db.ErrBusy):
+		w.Header().Set("Retry-After", "1")
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	writeError(w, status, err.Error())
+}
+
+// methodNotAllowed writes a 405 response listing the allowed methods.
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
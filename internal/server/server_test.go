@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/exporter"
+	"github.com/ashish0kumar/taskly/internal/memstore"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// do sends a request to h and returns the response.
+func do(t *testing.T, h http.Handler, method, target, body string, header ...string) *http.Response {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	req.Host = "localhost:8080"
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		if header[i] == "Host" {
+			req.Host = header[i+1]
+			continue
+		}
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+// decode parses a JSON response body into v.
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	h := New(memstore.New(), Options{}).Handler()
+
+	resp := do(t, h, http.MethodPost, "/api/tasks", `{"name":"Write API","project":"web"}`)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") != "/api/tasks/1" {
+		t.Fatalf("create: %d, Location %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	var created exporter.JSONTask
+	decode(t, resp, &created)
+	if created.Name != "Write API" || created.Status != "todo" {
+		t.Errorf("created task = %+v", created)
+	}
+	do(t, h, http.MethodPost, "/api/tasks", `{"name":"Other","status":"done"}`)
+
+	var listed []exporter.JSONTask
+	decode(t, do(t, h, http.MethodGet, "/api/tasks?project=web", ""), &listed)
+	if len(listed) != 1 || listed[0].ID != created.ID {
+		t.Errorf("list ?project=web = %+v", listed)
+	}
+	decode(t, do(t, h, http.MethodGet, "/api/tasks?status=done&sort=name:desc", ""), &listed)
+	if len(listed) != 1 || listed[0].Name != "Other" {
+		t.Errorf("list ?status=done = %+v", listed)
+	}
+
+	resp = do(t, h, http.MethodPatch, "/api/tasks/1", `{"status":"done"}`)
+	var updated exporter.JSONTask
+	decode(t, resp, &updated)
+	if resp.StatusCode != http.StatusOK || updated.Status != "done" || updated.Completed == nil {
+		t.Errorf("update: %d, %+v", resp.StatusCode, updated)
+	}
+
+	if resp := do(t, h, http.MethodDelete, "/api/tasks/1", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete: %d", resp.StatusCode)
+	}
+	if resp := do(t, h, http.MethodGet, "/api/tasks/1", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("get after delete: %d", resp.StatusCode)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	h := New(memstore.New(), Options{}).Handler()
+	do(t, h, http.MethodPost, "/api/tasks", `{"name":"Task"}`)
+
+	tag := do(t, h, http.MethodGet, "/api/tasks/1", "").Header.Get("ETag")
+	if tag == "" {
+		t.Fatal("GET returned no ETag")
+	}
+	if resp := do(t, h, http.MethodGet, "/api/tasks/1", "", "If-None-Match", tag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("GET with current If-None-Match: %d", resp.StatusCode)
+	}
+
+	resp := do(t, h, http.MethodPatch, "/api/tasks/1", `{"name":"Mine"}`, "If-Match", tag)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH with current If-Match: %d", resp.StatusCode)
+	}
+	if resp.Header.Get("ETag") == tag {
+		t.Error("ETag did not change after PATCH")
+	}
+
+	// The old tag is stale now, so both changes must be refused.
+	if resp := do(t, h, http.MethodPatch, "/api/tasks/1", `{"name":"Theirs"}`, "If-Match", tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PATCH with stale If-Match: %d", resp.StatusCode)
+	}
+	if resp := do(t, h, http.MethodDelete, "/api/tasks/1", "", "If-Match", tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with stale If-Match: %d", resp.StatusCode)
+	}
+	var got exporter.JSONTask
+	decode(t, do(t, h, http.MethodGet, "/api/tasks/1", ""), &got)
+	if got.Name != "Mine" {
+		t.Errorf("name after refused PATCH = %q", got.Name)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	h := New(memstore.New(), Options{WIPLimits: map[string]int{"in progress": 1}}).Handler()
+	do(t, h, http.MethodPost, "/api/tasks", `{"name":"Busy","status":"in progress"}`)
+
+	tests := []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPost, "/api/tasks", `{"name":""}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/tasks", `{"name":"x","due":"soon"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/tasks", `{"name":"x","status":"in progress"}`, http.StatusConflict},
+		{http.MethodGet, "/api/tasks?status=later", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/tasks?sort=size", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/tasks/99", "", http.StatusNotFound},
+		{http.MethodGet, "/api/tasks/abc", "", http.StatusNotFound},
+		{http.MethodPut, "/api/tasks/1", `{}`, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/nothing", "", http.StatusNotFound},
+		{http.MethodPatch, "/api/tasks/1", `{"status":"todo"}`, http.StatusUnsupportedMediaType},
+	}
+	for _, tc := range tests {
+		var header []string
+		if tc.want == http.StatusUnsupportedMediaType {
+			header = []string{"Content-Type", "text/plain"}
+		}
+		resp := do(t, h, tc.method, tc.target, tc.body, header...)
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s: %d, want %d", tc.method, tc.target, resp.StatusCode, tc.want)
+		}
+		var body apiError
+		decode(t, resp, &body)
+		if body.Error == "" {
+			t.Errorf("%s %s: error response has no message", tc.method, tc.target)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	h := New(memstore.New(), Options{Token: "s3cret"}).Handler()
+	if resp := do(t, h, http.MethodGet, "/api/tasks", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token: %d", resp.StatusCode)
+	}
+	if resp := do(t, h, http.MethodGet, "/api/tasks", "", "Authorization", "Bearer wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: %d", resp.StatusCode)
+	}
+	if resp := do(t, h, http.MethodGet, "/api/tasks", "", "Authorization", "Bearer s3cret"); resp.StatusCode != http.StatusOK {
+		t.Errorf("right token: %d", resp.StatusCode)
+	}
+
+	resp := do(t, h, http.MethodGet, "/api/openapi.json", "")
+	var doc map[string]interface{}
+	decode(t, resp, &doc)
+	if resp.StatusCode != http.StatusOK || doc["openapi"] == nil {
+		t.Errorf("openapi.json: %d, %v", resp.StatusCode, doc["openapi"])
+	}
+}
+
+func TestWebView(t *testing.T) {
+	h := New(memstore.New(), Options{Token: "s3cret"}).Handler()
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp := do(t, h, http.MethodGet, path, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s: %d", path, resp.StatusCode)
+		}
+	}
+	resp := do(t, h, http.MethodGet, "/", "")
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `<script src="app.js">`) {
+		t.Errorf("GET / did not serve the web view:\n%s", body)
+	}
+}
+
+// racingStore changes each task right after it is read, as another process
+// writing to the same store between a request's check and its write would.
+type racingStore struct {
+	db.Store
+}
+
+func (s racingStore) GetTaskContext(ctx context.Context, id uint) (task.Task, error) {
+	t, err := s.Store.GetTaskContext(ctx, id)
+	if err == nil {
+		time.Sleep(time.Millisecond)
+		name := t.Name + " (edited)"
+		_, err = s.Store.UpdateContext(ctx, id, db.Changes{Name: &name})
+	}
+	return t, err
+}
+
+func TestConditionalWriteRace(t *testing.T) {
+	store := memstore.New()
+	if _, err := store.InsertContext(context.Background(), "Task", ""); err != nil {
+		t.Fatal(err)
+	}
+	h := New(store, Options{}).Handler()
+	tag := do(t, h, http.MethodGet, "/api/tasks/1", "").Header.Get("ETag")
+
+	h = New(racingStore{store}, Options{}).Handler()
+	if resp := do(t, h, http.MethodPatch, "/api/tasks/1", `{"name":"Mine"}`, "If-Match", tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PATCH racing another change: %d, want 412", resp.StatusCode)
+	}
+	if got, _ := store.GetTaskContext(context.Background(), 1); got.Name == "Mine" {
+		t.Error("the stale PATCH overwrote the other change")
+	}
+
+	tag = do(t, New(store, Options{}).Handler(), http.MethodGet, "/api/tasks/1", "").Header.Get("ETag")
+	if resp := do(t, h, http.MethodDelete, "/api/tasks/1", "", "If-Match", tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("DELETE racing another change: %d, want 412", resp.StatusCode)
+	}
+	if _, err := store.GetTaskContext(context.Background(), 1); err != nil {
+		t.Errorf("the stale DELETE removed the task: %v", err)
+	}
+}
+
+func TestLoopbackHostWithoutToken(t *testing.T) {
+	h := New(memstore.New(), Options{}).Handler()
+	for host, want := range map[string]int{
+		"localhost:8080":    http.StatusOK,
+		"127.0.0.1:8080":    http.StatusOK,
+		"[::1]:8080":        http.StatusOK,
+		"app.localhost":     http.StatusOK,
+		"evil.example:8080": http.StatusForbidden,
+		"192.168.1.5:8080":  http.StatusForbidden,
+	} {
+		if resp := do(t, h, http.MethodGet, "/api/tasks", "", "Host", host); resp.StatusCode != want {
+			t.Errorf("GET with Host %s: %d, want %d", host, resp.StatusCode, want)
+		}
+	}
+
+	// A token protects the API however it is addressed.
+	h = New(memstore.New(), Options{Token: "secret"}).Handler()
+	if resp := do(t, h, http.MethodGet, "/api/tasks", "", "Host", "tasks.example", "Authorization", "Bearer secret"); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET with a token and another Host: %d", resp.StatusCode)
+	}
+}
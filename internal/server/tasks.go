@@ -0,0 +1,348 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/exporter"
+	"github.com/ashish0kumar/taskly/internal/hooks"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// createRequest is the body of POST /api/tasks.
+type createRequest struct {
+	Name    string   `json:"name"`
+	Project string   `json:"project"`
+	Status  string   `json:"status"` // Defaults to todo
+	Tags    []string `json:"tags"`
+	Due     string   `json:"due"` // YYYY-MM-DD; empty for none
+}
+
+// updateRequest is the body of PATCH /api/tasks/{id}; absent fields are
+// left unchanged.
+type updateRequest struct {
+	Name    *string   `json:"name"`
+	Project *string   `json:"project"`
+	Status  *string   `json:"status"`
+	Tags    *[]string `json:"tags"` // Replaces all tags
+	Due     *string   `json:"due"`  // YYYY-MM-DD; empty removes the due date
+}
+
+// parseDue reads a due date from a request body: YYYY-MM-DD, or empty for
+// none. Errors match db.ErrInvalidInput.
+func parseDue(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	due, err := time.Parse(task.DueLayout, s)
+	if err != nil {
+		return time.Time{}, db.Errorf(db.ErrInvalidInput, "invalid due date %q: use YYYY-MM-DD", s)
+	}
+	return due, nil
+}
+
+// handleTasks serves /api/tasks.
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.listTasks(w, r)
+	case http.MethodPost:
+		s.createTask(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodHead, http.MethodPost)
+	}
+}
+
+// handleTask serves /api/tasks/{id}.
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/api/tasks/"), 10, 0)
+	if err != nil || id == 0 {
+		writeError(w, http.StatusNotFound, "no such endpoint")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.getTask(w, r, uint(id))
+	case http.MethodPatch:
+		s.updateTask(w, r, uint(id))
+	case http.MethodDelete:
+		s.deleteTask(w, r, uint(id))
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodHead, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	tasks, err := s.store.ListTasksContext(r.Context(), opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	out := make([]exporter.JSONTask, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, exporter.ToJSON(t))
+	}
+	writeTagged(w, r, http.StatusOK, out)
+}
+
+// listOptions reads the filter and sort query parameters of a list request.
+func listOptions(r *http.Request) (db.ListOptions, error) {
+	q := r.URL.Query()
+	opts := db.ListOptions{Project: q.Get("project"), Tag: strings.ToLower(q.Get("tag"))}
+	for _, value := range q["status"] {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if _, err := task.ParseStatus(status); err != nil {
+				return opts, db.Errorf(db.ErrInvalidInput, "invalid status parameter: %w", err)
+			}
+			opts.Statuses = append(opts.Statuses, status)
+		}
+	}
+	if v := q.Get("hide_done"); v != "" {
+		hide, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, db.Errorf(db.ErrInvalidInput, "invalid hide_done parameter %q: use true or false", v)
+		}
+		opts.HideDone = hide
+	}
+	if v := q.Get("done_since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, db.Errorf(db.ErrInvalidInput, "invalid done_since parameter %q: use an RFC 3339 time", v)
+		}
+		opts.DoneSince = since
+	}
+	sort, err := db.ParseSortKeys(q.Get("sort"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Sort = sort
+	return opts, nil
+}
+
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request, id uint) {
+	t, err := s.store.GetTaskContext(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeTagged(w, r, http.StatusOK, exporter.ToJSON(t))
+}
+
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if req.Status == "" {
+		req.Status = task.Todo.String()
+	}
+	due, err := parseDue(req.Due)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := hooks.Insert(r.Context(), s.store, task.Task{Name: strings.TrimSpace(req.Name), Project: req.Project, Status: req.Status, Tags: req.Tags, Due: due}, s.wipGuard)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	s.feed.changed()
+	w.Header().Set("Location", fmt.Sprintf("/api/tasks/%d", t.ID))
+	writeTagged(w, r, http.StatusCreated, exporter.ToJSON(t))
+}
+
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request, id uint) {
+	var req updateRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		req.Name = &name
+	}
+	var due *time.Time
+	if req.Due != nil {
+		d, err := parseDue(*req.Due)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		due = &d
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.precondition(w, r, id)
+	if !ok {
+		return
+	}
+	ch := db.Changes{Name: req.Name, Project: req.Project, Status: req.Status, Tags: req.Tags, Due: due}
+	if r.Header.Get("If-Match") != "" {
+		// Another process, such as the CLI, may change the task between
+		// the check above and this write; the store refuses it then.
+		ch.IfUpdated = current.Updated
+	}
+	t, err := hooks.Update(r.Context(), s.store, id, ch, s.wipGuard)
+	if errors.Is(err, db.ErrConflict) && !ch.IfUpdated.IsZero() {
+		writeError(w, http.StatusPreconditionFailed, staleMessage(id))
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	s.feed.changed()
+	writeTagged(w, r, http.StatusOK, exporter.ToJSON(t))
+}
+
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request, id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.precondition(w, r, id)
+	if !ok {
+		return
+	}
+	var ifUpdated time.Time
+	if r.Header.Get("If-Match") != "" {
+		// As for updates, the store refuses the deletion if another
+		// process changed the task since the check.
+		ifUpdated = current.Updated
+	}
+	err := s.store.DeleteIfUpdatedContext(r.Context(), id, ifUpdated)
+	if errors.Is(err, db.ErrConflict) && !ifUpdated.IsZero() {
+		writeError(w, http.StatusPreconditionFailed, staleMessage(id))
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	s.feed.changed()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// precondition loads the task a change applies to and checks it against
+// the request's If-Match header, writing the error response and returning
+// false if the change must not go ahead.
+func (s *Server) precondition(w http.ResponseWriter, r *http.Request, id uint) (task.Task, bool) {
+	current, err := s.store.GetTaskContext(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return task.Task{}, false
+	}
+	if match := r.Header.Get("If-Match"); match != "" {
+		tag, err := etag(exporter.ToJSON(current))
+		if err != nil {
+			writeStoreError(w, err)
+			return task.Task{}, false
+		}
+		if !etagMatches(match, tag) {
+			w.Header().Set("ETag", tag)
+			writeError(w, http.StatusPreconditionFailed, staleMessage(id))
+			return task.Task{}, false
+		}
+	}
+	return current, true
+}
+
+// staleMessage is the error for a change whose If-Match no longer holds.
+func staleMessage(id uint) string {
+	return fmt.Sprintf("task %d was changed by someone else; fetch it again and retry", id)
+}
+
+// wipGuard refuses a change that moves a task, as left by any pre-hooks,
+// into a status at its configured work-in-progress limit.
+func (s *Server) wipGuard(ctx context.Context, before, after task.Task) error {
+	if after.Status == before.Status {
+		return nil
+	}
+	return s.checkWIPLimit(ctx, after.Status)
+}
+
+// checkWIPLimit refuses to add a task to status if that would exceed its
+// configured work-in-progress limit.
+func (s *Server) checkWIPLimit(ctx context.Context, status string) error {
+	limit, ok := s.opts.WIPLimits[status]
+	if !ok {
+		return nil
+	}
+	counts, err := s.store.CountTasksByStatusContext(ctx)
+	if err != nil {
+		return err
+	}
+	if counts[status] >= limit {
+		return db.Errorf(db.ErrConflict, "'%s' is at its WIP limit (%d/%d)", status, counts[status], limit)
+	}
+	return nil
+}
+
+// decodeBody parses the JSON request body into v, rejecting unknown fields.
+func decodeBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return db.Errorf(db.ErrInvalidInput, "invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// etag returns a strong entity tag for the JSON form of v.
+func etag(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header value
+// lists tag, or is "*". Weak tags never match, as this server only issues
+// strong ones.
+func etagMatches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTagged writes v with its ETag, answering a matching If-None-Match on
+// a GET or HEAD request with 304 Not Modified.
+func writeTagged(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	tag, err := etag(v)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("ETag", tag)
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, tag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	writeJSON(w, status, v)
+}
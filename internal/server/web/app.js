@@ -0,0 +1,274 @@
+// The taskly web view. Everything goes through the JSON API under /api/,
+// so the browser sees exactly what scripts and the taskly command see, and
+// the change feed keeps it up to date.
+"use strict";
+
+const statuses = ["todo", "in progress", "done"];
+const titles = { "todo": "Todo", "in progress": "In progress", "done": "Done" };
+const pollInterval = 3000;
+
+const main = document.getElementById("tasks");
+const message = document.getElementById("message");
+const filter = document.getElementById("filter");
+const hideDone = document.getElementById("hide-done");
+const layoutButton = document.getElementById("layout");
+const card = document.getElementById("card");
+
+let tasks = [];
+let listTag = "";
+let layout = localStorage.getItem("taskly.layout") || "list";
+let busy = false; // Set while editing or dragging, to hold off refreshes
+let live = false; // Set while following the change feed
+
+// authHeaders returns the headers carrying the saved API token, if any.
+function authHeaders() {
+  const token = localStorage.getItem("taskly.token");
+  return token ? { "Authorization": "Bearer " + token } : {};
+}
+
+// api sends a request to the API, asking for the bearer token when the
+// server wants one, and returns the response. Error responses throw with
+// the server's message.
+async function api(method, path, body) {
+  const headers = authHeaders();
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  if (method === "GET" && listTag && path.startsWith("/api/tasks?")) {
+    headers["If-None-Match"] = listTag;
+  }
+
+  const resp = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (resp.status === 401) {
+    const entered = prompt("This taskly server needs an API token:");
+    if (entered === null) throw new Error("an API token is required");
+    localStorage.setItem("taskly.token", entered.trim());
+    return api(method, path, body);
+  }
+  if (!resp.ok && resp.status !== 304) {
+    let text = resp.statusText;
+    try {
+      text = (await resp.json()).error || text;
+    } catch (_) {}
+    throw new Error(text);
+  }
+  return resp;
+}
+
+function showError(err) {
+  message.textContent = err.message;
+  message.hidden = false;
+}
+
+function clearError() {
+  message.hidden = true;
+}
+
+// refresh reloads the task list, redrawing only when it changed.
+async function refresh() {
+  if (busy) return;
+  const params = new URLSearchParams();
+  if (filter.value.trim()) params.set("project", filter.value.trim());
+  if (hideDone.checked) params.set("hide_done", "true");
+  try {
+    const resp = await api("GET", "/api/tasks?" + params);
+    if (resp.status !== 304) {
+      tasks = await resp.json();
+      listTag = resp.headers.get("ETag") || "";
+      render();
+    }
+    clearError();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+// change applies a change to a task and reloads the list.
+async function change(method, id, body) {
+  try {
+    await api(method, "/api/tasks/" + id, body);
+    clearError();
+  } catch (err) {
+    showError(err);
+  }
+  listTag = "";
+  await refresh();
+}
+
+function render() {
+  main.className = layout;
+  layoutButton.textContent = layout === "list" ? "Board" : "List";
+  main.replaceChildren(...statuses
+    .filter((status) => !(hideDone.checked && status === "done"))
+    .map(renderColumn));
+}
+
+function renderColumn(status) {
+  const column = document.createElement("section");
+  column.className = "column";
+  column.dataset.status = status;
+
+  const inColumn = tasks.filter((t) => t.status === status);
+  const heading = document.createElement("h2");
+  heading.textContent = `${titles[status]} (${inColumn.length})`;
+  column.append(heading);
+  if (inColumn.length === 0) {
+    const empty = document.createElement("p");
+    empty.className = "empty";
+    empty.textContent = "No tasks";
+    column.append(empty);
+  }
+  column.append(...inColumn.map(renderTask));
+
+  column.addEventListener("dragover", (e) => {
+    e.preventDefault();
+    column.classList.add("over");
+  });
+  column.addEventListener("dragleave", () => column.classList.remove("over"));
+  column.addEventListener("drop", (e) => {
+    e.preventDefault();
+    column.classList.remove("over");
+    busy = false; // dragend only fires after the drop
+    const id = e.dataTransfer.getData("text/plain");
+    const t = tasks.find((t) => String(t.id) === id);
+    if (t && t.status !== status) change("PATCH", id, { status });
+  });
+  return column;
+}
+
+function renderTask(t) {
+  const el = card.content.firstElementChild.cloneNode(true);
+  el.dataset.status = t.status;
+  el.querySelector(".name").textContent = t.name;
+  el.querySelector(".project").textContent = t.project;
+  el.querySelector(".tags").textContent = t.tags.join(", ");
+  editable(el.querySelector(".name"), t, "name");
+  editable(el.querySelector(".project"), t, "project");
+
+  const select = el.querySelector(".status");
+  for (const status of statuses) {
+    select.add(new Option(titles[status], status, false, status === t.status));
+  }
+  select.addEventListener("change", () => change("PATCH", t.id, { status: select.value }));
+
+  el.querySelector(".delete").addEventListener("click", () => {
+    if (confirm(`Delete "${t.name}"?`)) change("DELETE", t.id);
+  });
+
+  el.addEventListener("dragstart", (e) => {
+    busy = true;
+    el.classList.add("dragging");
+    e.dataTransfer.setData("text/plain", String(t.id));
+  });
+  el.addEventListener("dragend", () => {
+    busy = false;
+    el.classList.remove("dragging");
+    refresh(); // Catch up on changes that arrived while dragging
+  });
+  return el;
+}
+
+// editable lets a task field be edited in place: click to edit, Enter or
+// leaving the field saves, Escape cancels.
+function editable(el, t, field) {
+  el.addEventListener("click", () => {
+    if (el.isContentEditable) return;
+    busy = true;
+    el.contentEditable = "true";
+    el.focus();
+    document.getSelection().selectAllChildren(el);
+  });
+  el.addEventListener("keydown", (e) => {
+    if (e.key === "Enter") {
+      e.preventDefault();
+      el.blur();
+    } else if (e.key === "Escape") {
+      el.textContent = t[field];
+      el.blur();
+    }
+  });
+  el.addEventListener("blur", () => {
+    el.contentEditable = "false";
+    busy = false;
+    const value = el.textContent.trim();
+    if (value === t[field]) {
+      refresh(); // Catch up on changes that arrived while editing
+      return;
+    }
+    change("PATCH", t.id, { [field]: value });
+  });
+}
+
+document.getElementById("add").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const name = e.target.elements.namedItem("name");
+  const project = e.target.elements.namedItem("project");
+  try {
+    await api("POST", "/api/tasks", {
+      name: name.value.trim(),
+      project: project.value.trim(),
+    });
+    name.value = "";
+    clearError();
+  } catch (err) {
+    showError(err);
+  }
+  listTag = "";
+  refresh();
+});
+
+layoutButton.addEventListener("click", () => {
+  layout = layout === "list" ? "board" : "list";
+  localStorage.setItem("taskly.layout", layout);
+  render();
+});
+
+for (const input of [filter, hideDone]) {
+  input.addEventListener("input", () => {
+    listTag = "";
+    refresh();
+  });
+}
+
+// listen follows the server's change feed, reloading the list whenever a
+// task changes anywhere. EventSource cannot send the API token, so the
+// stream is read with fetch. After a disconnect it reconnects with the
+// last event ID, and the server replays what was missed.
+async function listen() {
+  let lastID = "";
+  for (;;) {
+    try {
+      const headers = authHeaders();
+      if (lastID) headers["Last-Event-ID"] = lastID;
+      const resp = await fetch("/api/events", { headers });
+      if (!resp.ok) throw new Error(resp.statusText);
+
+      live = true;
+      const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+      let buffer = "";
+      for (;;) {
+        const { value, done } = await reader.read();
+        if (done) break;
+        buffer += value;
+        const blocks = buffer.split("\n\n");
+        buffer = blocks.pop();
+        for (const block of blocks) {
+          const id = block.match(/^id: (.*)$/m);
+          if (!id) continue; // A keep-alive comment
+          lastID = id[1];
+          refresh();
+        }
+      }
+    } catch (_) {
+      // Fall through to reconnect; refresh reports lasting errors.
+    }
+    live = false;
+    await new Promise((resolve) => setTimeout(resolve, pollInterval));
+  }
+}
+
+refresh();
+listen();
+setInterval(() => live || refresh(), pollInterval);
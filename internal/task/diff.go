@@ -0,0 +1,71 @@
+package task
+
+import (
+	"sort"
+	"strings"
+)
+
+// ChangeType says what happened to a task between two snapshots. Exported
+type ChangeType string
+
+// The kinds of change Diff reports.
+const (
+	Created ChangeType = "created"
+	Updated ChangeType = "updated"
+	Deleted ChangeType = "deleted"
+)
+
+// Change describes one change to a task. For Deleted changes, Task is the
+// task as it was before. Exported
+type Change struct {
+	Type     ChangeType
+	Task     Task
+	Previous Task // For Updated changes, the task before the change
+}
+
+// Snapshot indexes tasks by ID, for comparing with Diff. Exported
+func Snapshot(tasks []Task) map[uint]Task {
+	byID := make(map[uint]Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	return byID
+}
+
+// Diff returns the changes that turn snapshot prev into next, in task ID
+// order. Exported
+func Diff(prev, next map[uint]Task) []Change {
+	ids := make([]uint, 0, len(next))
+	for id := range next {
+		ids = append(ids, id)
+	}
+	for id := range prev {
+		if _, ok := next[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var changes []Change
+	for _, id := range ids {
+		before, had := prev[id]
+		after, has := next[id]
+		switch {
+		case !had:
+			changes = append(changes, Change{Type: Created, Task: after})
+		case !has:
+			changes = append(changes, Change{Type: Deleted, Task: before})
+		case !same(before, after):
+			changes = append(changes, Change{Type: Updated, Task: after, Previous: before})
+		}
+	}
+	return changes
+}
+
+// same reports whether a and b have identical fields.
+func same(a, b Task) bool {
+	return a.Name == b.Name && a.Project == b.Project && a.Status == b.Status &&
+		a.Position == b.Position && a.Created.Equal(b.Created) &&
+		a.Updated.Equal(b.Updated) && a.Completed.Equal(b.Completed) && a.Due.Equal(b.Due) &&
+		strings.Join(a.Tags, " ") == strings.Join(b.Tags, " ")
+}
@@ -0,0 +1,59 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DueLayout is the format of due dates in files, JSON and flags. Exported
+const DueLayout = "2006-01-02"
+
+// Day returns the calendar date of t, in t's location, as midnight UTC, the
+// form in which due dates are kept. The zero time stays zero. Exported
+func Day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// FormatDue formats a due date as YYYY-MM-DD, or "" when there is none.
+// Exported
+func FormatDue(due time.Time) string {
+	if due.IsZero() {
+		return ""
+	}
+	return due.UTC().Format(DueLayout)
+}
+
+// ParseDue reads a due date relative to now: a date like 2026-10-31,
+// "today", "tomorrow", or a number of days or weeks ahead such as 3d or 2w.
+// An empty value or "none" means no due date and returns the zero time.
+// Exported
+func ParseDue(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "none":
+		return time.Time{}, nil
+	case "today":
+		return Day(now), nil
+	case "tomorrow":
+		return Day(now).AddDate(0, 0, 1), nil
+	}
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		if count, err := strconv.Atoi(strings.TrimPrefix(s[:n-1], "+")); err == nil && count >= 0 {
+			if s[n-1] == 'w' {
+				count *= 7
+			}
+			return Day(now).AddDate(0, 0, count), nil
+		}
+	}
+	due, err := time.Parse(DueLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid due date %q: use YYYY-MM-DD, today, tomorrow or a number of days or weeks ahead like 3d or 2w", s)
+	}
+	return due, nil
+}
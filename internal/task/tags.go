@@ -0,0 +1,59 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ParseTags splits a list of tags separated by commas or spaces, such as
+// "home, errands", and normalises it like NormalizeTags. Exported
+func ParseTags(s string) []string {
+	return NormalizeTags(strings.FieldsFunc(s, isTagSeparator))
+}
+
+// isTagSeparator reports whether r separates tags in a list.
+func isTagSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+// NormalizeTags returns tags lower-cased, trimmed, sorted and without
+// duplicates or empty entries. The result is nil when no tags remain.
+// Exported
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ValidateTag checks that tag can be stored: it must be non-empty and
+// contain no spaces or commas, which separate tags. Exported
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("tags cannot be empty")
+	}
+	if strings.IndexFunc(tag, isTagSeparator) >= 0 {
+		return fmt.Errorf("tag %q cannot contain spaces or commas", tag)
+	}
+	return nil
+}
+
+// HasTag reports whether the task is tagged with tag. Exported
+func (t Task) HasTag(tag string) bool {
+	for _, have := range t.Tags {
+		if have == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -24,13 +25,28 @@ func (s Status) String() string {
 	return statuses[s]
 }
 
+// ParseStatus returns the Status whose String form is s.
+func ParseStatus(s string) (Status, error) {
+	for _, st := range AllStatuses() {
+		if st.String() == s {
+			return st, nil
+		}
+	}
+	return Todo, fmt.Errorf("unknown status %q", s)
+}
+
 // Task represents a single task item. Exported for use in other packages.
 type Task struct {
-	ID      uint
-	Name    string
-	Project string // Use string, handle NULL in DB layer scan
-	Status  string // Store as string representation from Status enum
-	Created time.Time
+	ID        uint
+	Name      string
+	Project   string // Use string, handle NULL in DB layer scan
+	Status    string // Store as string representation from Status enum
+	Created   time.Time
+	Updated   time.Time // Last modification time; equals Created for untouched tasks
+	Completed time.Time // When the task was marked done; zero unless Status is done
+	Position  float64   // Manual rank; lower values come first within a status
+	Tags      []string  // Sorted and lower-case; see NormalizeTags
+	Due       time.Time // Day the task is due, as midnight UTC (see Day); zero for none
 }
 
 // list.Item implementation for Bubble Tea lists
@@ -38,10 +54,17 @@ type Task struct {
 func (t Task) FilterValue() string { return t.Name }
 func (t Task) Title() string       { return t.Name }
 func (t Task) Description() string {
+	var parts []string
 	if t.Project != "" {
-		return fmt.Sprintf("Project: %s", t.Project)
+		parts = append(parts, fmt.Sprintf("Project: %s", t.Project))
+	}
+	if !t.Due.IsZero() {
+		parts = append(parts, "Due: "+FormatDue(t.Due))
+	}
+	if len(t.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(t.Tags, " #"))
 	}
-	return ""
+	return strings.Join(parts, "  ")
 }
 
 // kancli.Status implementation (Exported methods on exported Status type)
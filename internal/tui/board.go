@@ -0,0 +1,360 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/kancli"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// boardKeys are the editing bindings added on top of kancli's navigation.
+var boardKeys = struct {
+	New, Edit, Delete, Move, RankUp, RankDown, Quit key.Binding
+}{
+	New:      key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new")),
+	Edit:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+	Delete:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+	Move:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "move to next column")),
+	RankUp:   key.NewBinding(key.WithKeys("shift+up", "K"), key.WithHelp("shift+↑/K", "move up")),
+	RankDown: key.NewBinding(key.WithKeys("shift+down", "J"), key.WithHelp("shift+↓/J", "move down")),
+	Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+// columnTitles names the board columns, indexed by task.Status.
+var columnTitles = []string{"To Do", "In Progress", "Done"}
+
+// pollInterval is how often the board checks the database for changes made
+// by other processes.
+const pollInterval = time.Second
+
+// pollMsg reports the result of one database change check.
+type pollMsg struct {
+	changed bool
+	err     error
+}
+
+// Board is a kanban board that saves changes made on it. It wraps a kancli
+// board, rebuilding its columns from the database after every change,
+// including changes made by other taskly processes. Exported
+type Board struct {
+	db       backend
+	opts     db.ListOptions
+	limits   map[string]int // WIP limits by status name
+	counts   map[string]int // Tasks per status across all projects
+	watcher  db.ChangeWatcher
+	board    *kancli.Board
+	size     *tea.WindowSizeMsg
+	form     form
+	mode     mode
+	pending  task.Task // Task being edited or awaiting delete confirmation
+	status   string
+	quitting bool
+}
+
+// NewBoard creates a kanban board showing the tasks in tdb that match opts.
+// Columns whose status has an entry in limits show their count against the
+// limit and refuse tasks once full. Call Close once the board is no longer
+// running. Each store operation is given at most timeout to finish; zero
+// means no limit. Exported
+func NewBoard(tdb db.Store, opts db.ListOptions, limits map[string]int, timeout time.Duration) (*Board, error) {
+	watcher, err := tdb.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	b := &Board{db: backend{tdb, timeout}, opts: opts, limits: limits, watcher: watcher}
+	b.board = b.buildBoard(nil, 0)
+	return b, nil
+}
+
+// Close stops watching the database for changes.
+func (b *Board) Close() error {
+	return b.watcher.Close()
+}
+
+func (b *Board) Init() tea.Cmd {
+	return tea.Batch(loadTasks(b.db, b.opts, 0), b.poll())
+}
+
+// poll checks for outside changes after pollInterval.
+func (b *Board) poll() tea.Cmd {
+	watcher := b.watcher
+	return tea.Tick(pollInterval, func(time.Time) tea.Msg {
+		changed, err := watcher.Changed()
+		return pollMsg{changed: changed, err: err}
+	})
+}
+
+func (b *Board) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.size = &msg
+
+	case tasksLoadedMsg:
+		b.counts = msg.counts
+		b.board = b.buildBoard(msg.tasks, msg.selectID)
+		return b, b.resize()
+
+	case mutatedMsg:
+		b.status = msg.status
+		return b, loadTasks(b.db, b.opts, msg.selectID)
+
+	case pollMsg:
+		if msg.err != nil {
+			b.status = "Error: " + msg.err.Error()
+			return b, b.poll()
+		}
+		if msg.changed {
+			// Selections are kept by buildBoard.
+			return b, tea.Batch(loadTasks(b.db, b.opts, 0), b.poll())
+		}
+		return b, b.poll()
+
+	case errMsg:
+		b.status = "Error: " + msg.err.Error()
+		return b, nil
+
+	case tea.KeyMsg:
+		switch b.mode {
+		case editing:
+			return b.updateForm(msg)
+		case confirmingDelete:
+			b.mode = browsing
+			if key.Matches(msg, keys.Confirm) {
+				return b, deleteTask(b.db, b.pending)
+			}
+			b.status = "Delete cancelled."
+			return b, nil
+		}
+
+		switch {
+		case key.Matches(msg, boardKeys.New):
+			// New tasks on a filtered board belong to its project and tag.
+			nt := task.Task{Project: b.opts.Project}
+			if b.opts.Tag != "" {
+				nt.Tags = []string{b.opts.Tag}
+			}
+			b.form = newForm(nt)
+			b.mode = editing
+			return b, textinput.Blink
+		case key.Matches(msg, boardKeys.Edit):
+			if t, ok := b.selectedTask(); ok {
+				b.pending = t
+				b.form = newForm(t)
+				b.mode = editing
+				return b, textinput.Blink
+			}
+			return b, nil
+		case key.Matches(msg, boardKeys.Delete):
+			if t, ok := b.selectedTask(); ok {
+				b.pending = t
+				b.mode = confirmingDelete
+				b.status = fmt.Sprintf("Delete '%s'? (y/N)", t.Name)
+			}
+			return b, nil
+		case key.Matches(msg, boardKeys.Move):
+			if t, ok := b.selectedTask(); ok {
+				next := statusAfter(t, true)
+				if b.full(next) {
+					return b, nil
+				}
+				return b, moveTask(b.db, t, next)
+			}
+			return b, nil
+		case key.Matches(msg, boardKeys.RankUp):
+			return b, b.reorder(-1)
+		case key.Matches(msg, boardKeys.RankDown):
+			return b, b.reorder(1)
+		}
+	}
+
+	if b.mode == editing {
+		var cmd tea.Cmd
+		b.form, cmd = b.form.update(msg)
+		return b, cmd
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, boardKeys.Quit) {
+		b.quitting = true
+		return b, tea.Quit
+	}
+
+	res, cmd := b.board.Update(msg)
+	if board, ok := res.(*kancli.Board); ok {
+		b.board = board
+	}
+	return b, cmd
+}
+
+// updateForm handles keys while the new/edit form is open.
+func (b *Board) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		b.mode = browsing
+		b.status = ""
+		return b, nil
+	case key.Matches(msg, keys.NextField):
+		b.form.toggleFocus()
+		return b, textinput.Blink
+	case key.Matches(msg, keys.Submit):
+		nt, err := b.form.values()
+		if err != nil {
+			b.status = err.Error()
+			return b, nil
+		}
+		if nt.Name == "" {
+			b.status = "A task needs a name."
+			return b, nil
+		}
+		b.mode = browsing
+		if b.form.taskID == 0 {
+			nt.Status = task.Status(b.board.Focused).String()
+			if b.full(nt.Status) {
+				return b, nil
+			}
+			return b, addTask(b.db, nt)
+		}
+		return b, editTask(b.db, b.pending, nt)
+	}
+	var cmd tea.Cmd
+	b.form, cmd = b.form.update(msg)
+	return b, cmd
+}
+
+// full reports whether status is at its WIP limit, explaining why in the
+// status line if so.
+func (b *Board) full(status string) bool {
+	limit, ok := b.limits[status]
+	if !ok || b.counts[status] < limit {
+		return false
+	}
+	b.status = fmt.Sprintf("'%s' is at its WIP limit (%d/%d).", status, b.counts[status], limit)
+	return true
+}
+
+// reorder swaps the selected task with its neighbour offset places away in
+// the focused column.
+func (b *Board) reorder(offset int) tea.Cmd {
+	col := b.board.Cols[b.board.Focused].List
+	items := col.VisibleItems()
+	idx := col.Index()
+	if idx < 0 || idx >= len(items) || idx+offset < 0 || idx+offset >= len(items) {
+		return nil
+	}
+	t, ok := items[idx].(task.Task)
+	other, otherOK := items[idx+offset].(task.Task)
+	if !ok || !otherOK {
+		return nil
+	}
+	return reorderTask(b.db, t, other, offset < 0)
+}
+
+// selectedTask returns the task under the cursor in the focused column.
+func (b *Board) selectedTask() (task.Task, bool) {
+	t, ok := b.board.Cols[b.board.Focused].List.SelectedItem().(task.Task)
+	return t, ok
+}
+
+// buildBoard creates a kancli board from tasks, keeping the currently
+// focused column and each column's selected task. If selectID is present,
+// that task is selected and its column focused instead.
+func (b *Board) buildBoard(tasks []task.Task, selectID uint) *kancli.Board {
+	focused := 0
+	prevSelected := map[uint]bool{}
+	if b.board != nil {
+		focused = int(b.board.Focused)
+		for _, col := range b.board.Cols {
+			if t, ok := col.List.SelectedItem().(task.Task); ok {
+				prevSelected[t.ID] = true
+			}
+		}
+	}
+
+	statuses := task.AllStatuses()
+	items := make([][]list.Item, len(statuses))
+	selected := make([]int, len(statuses))
+	for i := range selected {
+		selected[i] = -1
+	}
+	for _, t := range tasks {
+		s, err := task.ParseStatus(t.Status)
+		if err != nil {
+			continue
+		}
+		if t.ID == selectID {
+			selected[s] = len(items[s])
+			focused = int(s)
+		} else if prevSelected[t.ID] && selected[s] < 0 {
+			selected[s] = len(items[s])
+		}
+		items[s] = append(items[s], t)
+	}
+
+	cols := make([]kancli.Column, len(statuses))
+	for i, s := range statuses {
+		cols[i] = kancli.NewColumn(items[i], s, i == focused)
+	}
+	board := kancli.NewDefaultBoard(cols)
+	for i, idx := range selected {
+		if idx >= 0 {
+			board.Cols[i].List.Select(idx)
+		}
+		board.Cols[i].List.Title = b.columnTitle(statuses[i], len(items[i]))
+		if limit, ok := b.limits[statuses[i].String()]; ok && b.counts[statuses[i].String()] > limit {
+			board.Cols[i].List.Styles.Title = board.Cols[i].List.Styles.Title.Background(lipgloss.Color("160"))
+		}
+	}
+	return board
+}
+
+// columnTitle labels a column with its task count, or its count against
+// the WIP limit, and the filters that apply to it, e.g. "In Progress 2/3"
+// or "Done (4) · web · #urgent · since Oct 12".
+func (b *Board) columnTitle(s task.Status, count int) string {
+	title := fmt.Sprintf("%s (%d)", columnTitles[s], count)
+	if limit, ok := b.limits[s.String()]; ok {
+		title = fmt.Sprintf("%s %d/%d", columnTitles[s], b.counts[s.String()], limit)
+	}
+	if b.opts.Project != "" {
+		title += " · " + b.opts.Project
+	}
+	if b.opts.Tag != "" {
+		title += " · #" + b.opts.Tag
+	}
+	if s == task.Done {
+		if b.opts.HideDone {
+			title = columnTitles[s] + " (hidden)"
+		} else if !b.opts.DoneSince.IsZero() {
+			title += " · since " + b.opts.DoneSince.Local().Format("Jan 2")
+		}
+	}
+	return title
+}
+
+// resize replays the last window size to a freshly built board, which
+// otherwise renders nothing until the terminal is resized.
+func (b *Board) resize() tea.Cmd {
+	if b.size == nil {
+		return nil
+	}
+	_, cmd := b.board.Update(*b.size)
+	return cmd
+}
+
+func (b *Board) View() string {
+	if b.quitting {
+		return ""
+	}
+	if b.mode == editing {
+		return paneStyle.Render(b.form.view())
+	}
+	help := helpStyle.Render("n new • e edit • d delete • enter move to next column • shift+↑/↓ reorder")
+	return lipgloss.JoinVertical(lipgloss.Left, b.board.View(), help, statusStyle.Render(b.status))
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// backend is the store the TUI works on, together with the time limit for
+// each operation on it.
+type backend struct {
+	db.Store
+	timeout time.Duration // Zero means no limit
+}
+
+// context returns a context for one store operation, bounded by the
+// backend's timeout when one is set.
+func (b backend) context() (context.Context, context.CancelFunc) {
+	if b.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+// undoAction reverts a single change made from the TUI.
+type undoAction struct {
+	description string
+	selectID    uint
+	revert      func(ctx context.Context, tdb backend) error
+}
+
+// tasksLoadedMsg carries a fresh copy of the tasks and the number of tasks
+// in each status across the whole database.
+type tasksLoadedMsg struct {
+	tasks    []task.Task
+	counts   map[string]int
+	selectID uint
+}
+
+// mutatedMsg reports a successful change and how to undo it.
+type mutatedMsg struct {
+	status   string
+	selectID uint
+	undo     *undoAction
+	remind   bool // Point out that the change can be undone
+}
+
+// errMsg reports a failed database operation.
+type errMsg struct{ err error }
+
+// statusAfter returns the status one step forward or back from t's status,
+// wrapping around at either end.
+func statusAfter(t task.Task, forward bool) string {
+	current, err := task.ParseStatus(t.Status)
+	if err != nil {
+		return task.Todo.String()
+	}
+	if forward {
+		return task.Status(current.Next()).String()
+	}
+	return task.Status(current.Prev()).String()
+}
+
+func loadTasks(tdb backend, opts db.ListOptions, selectID uint) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		tasks, err := tdb.ListTasksContext(ctx, opts)
+		if err != nil {
+			return errMsg{err}
+		}
+		counts, err := tdb.CountTasksByStatusContext(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return tasksLoadedMsg{tasks: tasks, counts: counts, selectID: selectID}
+	}
+}
+
+func addTask(tdb backend, nt task.Task) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		t, err := tdb.InsertTaskContext(ctx, nt)
+		if err != nil {
+			return errMsg{err}
+		}
+		return mutatedMsg{
+			status:   fmt.Sprintf("Task ('%s') added.", t.Name),
+			selectID: t.ID,
+			undo: &undoAction{
+				description: fmt.Sprintf("add of '%s'", t.Name),
+				revert:      func(ctx context.Context, tdb backend) error { return tdb.DeleteContext(ctx, t.ID) },
+			},
+		}
+	}
+}
+
+func editTask(tdb backend, orig, edited task.Task) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		t, err := tdb.UpdateContext(ctx, orig.ID, db.Changes{Name: &edited.Name, Project: &edited.Project, Tags: &edited.Tags, Due: &edited.Due})
+		if err != nil {
+			return errMsg{err}
+		}
+		return mutatedMsg{
+			status:   fmt.Sprintf("Task ('%s') updated.", t.Name),
+			selectID: t.ID,
+			undo: &undoAction{
+				description: fmt.Sprintf("edit of '%s'", t.Name),
+				selectID:    orig.ID,
+				revert: func(ctx context.Context, tdb backend) error {
+					_, err := tdb.UpdateContext(ctx, orig.ID, db.Changes{Name: &orig.Name, Project: &orig.Project, Tags: &orig.Tags, Due: &orig.Due})
+					return err
+				},
+			},
+		}
+	}
+}
+
+func moveTask(tdb backend, orig task.Task, status string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		if _, err := tdb.UpdateContext(ctx, orig.ID, db.Changes{Status: &status}); err != nil {
+			return errMsg{err}
+		}
+		return mutatedMsg{
+			status:   fmt.Sprintf("Task ('%s') moved to %s.", orig.Name, status),
+			selectID: orig.ID,
+			undo: &undoAction{
+				description: fmt.Sprintf("move of '%s'", orig.Name),
+				selectID:    orig.ID,
+				revert: func(ctx context.Context, tdb backend) error {
+					_, err := tdb.UpdateContext(ctx, orig.ID, db.Changes{Status: &orig.Status})
+					return err
+				},
+			},
+		}
+	}
+}
+
+func deleteTask(tdb backend, orig task.Task) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		if err := tdb.DeleteContext(ctx, orig.ID); err != nil {
+			return errMsg{err}
+		}
+		return mutatedMsg{
+			status: fmt.Sprintf("Task ('%s') deleted.", orig.Name),
+			remind: true,
+			undo: &undoAction{
+				description: fmt.Sprintf("delete of '%s'", orig.Name),
+				selectID:    orig.ID,
+				revert:      func(ctx context.Context, tdb backend) error { return tdb.RestoreContext(ctx, orig) },
+			},
+		}
+	}
+}
+
+// reorderTask ranks t immediately before or after other.
+func reorderTask(tdb backend, t, other task.Task, before bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		var err error
+		if before {
+			err = tdb.MoveBeforeContext(ctx, t.ID, other.ID)
+		} else {
+			err = tdb.MoveAfterContext(ctx, t.ID, other.ID)
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+		return mutatedMsg{selectID: t.ID}
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Form fields, in tab order.
+const (
+	nameField = iota
+	projectField
+	tagsField
+	dueField
+	fieldCount
+)
+
+// form edits a task's name, project, tags and due date. A zero taskID means a new task.
+type form struct {
+	taskID uint
+	fields [fieldCount]textinput.Model
+	focus  int
+}
+
+// newForm returns a form prefilled with t, focused on the name field.
+func newForm(t task.Task) form {
+	name := textinput.New()
+	name.Placeholder = "task name"
+	name.Prompt = "Name:    "
+	name.CharLimit = 256
+	name.SetValue(t.Name)
+	name.Focus()
+
+	project := textinput.New()
+	project.Placeholder = "project (optional)"
+	project.Prompt = "Project: "
+	project.CharLimit = 128
+	project.SetValue(t.Project)
+
+	tags := textinput.New()
+	tags.Placeholder = "tags, separated by commas (optional)"
+	tags.Prompt = "Tags:    "
+	tags.CharLimit = 256
+	tags.SetValue(strings.Join(t.Tags, ", "))
+
+	due := textinput.New()
+	due.Placeholder = "YYYY-MM-DD, today, tomorrow or 3d (optional)"
+	due.Prompt = "Due:     "
+	due.CharLimit = 32
+	due.SetValue(task.FormatDue(t.Due))
+
+	return form{taskID: t.ID, fields: [fieldCount]textinput.Model{name, project, tags, due}}
+}
+
+// values returns the task described by the trimmed field contents, or an
+// error if the due date cannot be read.
+func (f form) values() (task.Task, error) {
+	due, err := task.ParseDue(f.fields[dueField].Value(), time.Now())
+	if err != nil {
+		return task.Task{}, err
+	}
+	return task.Task{
+		Name:    strings.TrimSpace(f.fields[nameField].Value()),
+		Project: strings.TrimSpace(f.fields[projectField].Value()),
+		Tags:    task.ParseTags(f.fields[tagsField].Value()),
+		Due:     due,
+	}, nil
+}
+
+// toggleFocus moves the cursor to the next field, wrapping around.
+func (f *form) toggleFocus() {
+	f.fields[f.focus].Blur()
+	f.focus = (f.focus + 1) % fieldCount
+	f.fields[f.focus].Focus()
+}
+
+// update forwards input to the focused field.
+func (f form) update(msg tea.Msg) (form, tea.Cmd) {
+	var cmd tea.Cmd
+	f.fields[f.focus], cmd = f.fields[f.focus].Update(msg)
+	return f, cmd
+}
+
+func (f form) view() string {
+	title := "New task"
+	if f.taskID != 0 {
+		title = "Edit task"
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(title),
+		"",
+		f.fields[nameField].View(),
+		f.fields[projectField].View(),
+		f.fields[tagsField].View(),
+		f.fields[dueField].View(),
+		"",
+		helpStyle.Render("tab next field • enter save • esc cancel"),
+	)
+}
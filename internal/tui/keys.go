@@ -0,0 +1,71 @@
+package tui
+
+import "github.com/charmbracelet/bubbles/key"
+
+// keyMap holds the task management bindings layered on top of the list's
+// own navigation and filtering keys.
+type keyMap struct {
+	Add        key.Binding
+	Edit       key.Binding
+	NextStatus key.Binding
+	PrevStatus key.Binding
+	Delete     key.Binding
+	Undo       key.Binding
+	Confirm    key.Binding
+	Cancel     key.Binding
+	NextField  key.Binding
+	Submit     key.Binding
+}
+
+var keys = keyMap{
+	Add: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit"),
+	),
+	NextStatus: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "next status"),
+	),
+	PrevStatus: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "prev status"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d", "x"),
+		key.WithHelp("d", "delete"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo"),
+	),
+	Confirm: key.NewBinding(
+		key.WithKeys("y", "Y"),
+		key.WithHelp("y", "confirm"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+	NextField: key.NewBinding(
+		key.WithKeys("tab", "shift+tab", "up", "down"),
+		key.WithHelp("tab", "switch field"),
+	),
+	Submit: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "save"),
+	),
+}
+
+// shortHelp lists the bindings shown beneath the task list.
+func (k keyMap) shortHelp() []key.Binding {
+	return []key.Binding{k.Add, k.Edit, k.NextStatus, k.Delete, k.Undo}
+}
+
+// fullHelp lists the bindings shown in the list's expanded help.
+func (k keyMap) fullHelp() []key.Binding {
+	return []key.Binding{k.Add, k.Edit, k.NextStatus, k.PrevStatus, k.Delete, k.Undo}
+}
@@ -0,0 +1,321 @@
+// Package tui implements taskly's interactive full-screen task manager.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	labelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Width(10)
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	paneStyle   = lipgloss.NewStyle().
+			Padding(1, 2).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62"))
+)
+
+// mode is what the keyboard currently controls.
+type mode int
+
+const (
+	browsing mode = iota
+	editing
+	confirmingDelete
+)
+
+// Model is the Bubble Tea model for the task manager. Exported
+type Model struct {
+	db     backend
+	list   list.Model
+	form   form
+	mode   mode
+	undo   []undoAction
+	status string
+	width  int
+	height int
+	limits map[string]int // WIP limits by status name
+	counts map[string]int // Tasks per status as of the last load
+}
+
+// New creates a task manager backed by tdb. Adding or moving a task into a
+// status that has reached its entry in limits is refused, as on the Kanban
+// board. Each store operation is given at most timeout to finish; zero
+// means no limit. Exported
+func New(tdb db.Store, limits map[string]int, timeout time.Duration) Model {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Tasks"
+	l.Styles.Title = l.Styles.Title.Background(lipgloss.Color("62"))
+	l.SetStatusBarItemName("task", "tasks")
+	// Free up d and u, which the list binds to paging by default.
+	l.KeyMap.PrevPage = key.NewBinding(key.WithKeys("left", "h", "pgup", "b"), key.WithHelp("←/h/pgup", "prev page"))
+	l.KeyMap.NextPage = key.NewBinding(key.WithKeys("right", "l", "pgdown", "f"), key.WithHelp("→/l/pgdn", "next page"))
+	l.AdditionalShortHelpKeys = keys.shortHelp
+	l.AdditionalFullHelpKeys = keys.fullHelp
+
+	return Model{db: backend{tdb, timeout}, list: l, limits: limits}
+}
+
+func (m Model) Init() tea.Cmd {
+	return loadTasks(m.db, db.ListOptions{}, 0)
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.SetSize(m.listWidth(), m.height-2)
+		return m, nil
+
+	case tasksLoadedMsg:
+		items := make([]list.Item, len(msg.tasks))
+		selected := -1
+		for i, t := range msg.tasks {
+			items[i] = t
+			if t.ID == msg.selectID {
+				selected = i
+			}
+		}
+		m.counts = msg.counts
+		cmd := m.list.SetItems(items)
+		if selected >= 0 && !m.list.IsFiltered() {
+			m.list.Select(selected)
+		}
+		return m, cmd
+
+	case mutatedMsg:
+		if msg.undo != nil {
+			m.undo = append(m.undo, *msg.undo)
+		}
+		m.status = msg.status
+		if msg.remind {
+			// The board has no undo, so only the TUI says so.
+			m.status += " Press u to undo."
+		}
+		return m, loadTasks(m.db, db.ListOptions{}, msg.selectID)
+
+	case errMsg:
+		m.status = "Error: " + msg.err.Error()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case editing:
+			return m.updateForm(msg)
+		case confirmingDelete:
+			m.mode = browsing
+			t, ok := m.selectedTask()
+			if ok && key.Matches(msg, keys.Confirm) {
+				return m, deleteTask(m.db, t)
+			}
+			m.status = "Delete cancelled."
+			return m, nil
+		}
+
+		// While the filter prompt is open every key belongs to it.
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, keys.Add):
+			m.form = newForm(task.Task{})
+			m.mode = editing
+			return m, textinput.Blink
+		case key.Matches(msg, keys.Edit):
+			if t, ok := m.selectedTask(); ok {
+				m.form = newForm(t)
+				m.mode = editing
+				return m, textinput.Blink
+			}
+		case key.Matches(msg, keys.NextStatus):
+			if t, ok := m.selectedTask(); ok {
+				next := statusAfter(t, true)
+				if m.full(next) {
+					return m, nil
+				}
+				return m, moveTask(m.db, t, next)
+			}
+		case key.Matches(msg, keys.PrevStatus):
+			if t, ok := m.selectedTask(); ok {
+				prev := statusAfter(t, false)
+				if m.full(prev) {
+					return m, nil
+				}
+				return m, moveTask(m.db, t, prev)
+			}
+		case key.Matches(msg, keys.Delete):
+			if t, ok := m.selectedTask(); ok {
+				m.mode = confirmingDelete
+				m.status = fmt.Sprintf("Delete '%s'? (y/N)", t.Name)
+			}
+			return m, nil
+		case key.Matches(msg, keys.Undo):
+			return m, m.popUndo()
+		}
+	}
+
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	if m.mode == editing {
+		// Keep the cursor blinking while the form is open.
+		m.form, cmd = m.form.update(msg)
+		cmds = append(cmds, cmd)
+	}
+	m.list, cmd = m.list.Update(msg)
+	cmds = append(cmds, cmd)
+	return m, tea.Batch(cmds...)
+}
+
+// updateForm handles keys while the add/edit form is open.
+func (m Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.mode = browsing
+		m.status = ""
+		return m, nil
+	case key.Matches(msg, keys.NextField):
+		m.form.toggleFocus()
+		return m, textinput.Blink
+	case key.Matches(msg, keys.Submit):
+		nt, err := m.form.values()
+		if err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+		if nt.Name == "" {
+			m.status = "A task needs a name."
+			return m, nil
+		}
+		m.mode = browsing
+		if m.form.taskID == 0 {
+			if m.full(task.Todo.String()) {
+				return m, nil
+			}
+			return m, addTask(m.db, nt)
+		}
+		if t, ok := m.findTask(m.form.taskID); ok {
+			return m, editTask(m.db, t, nt)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.form, cmd = m.form.update(msg)
+	return m, cmd
+}
+
+// full reports whether status is at its WIP limit, explaining why in the
+// status line if so.
+func (m *Model) full(status string) bool {
+	limit, ok := m.limits[status]
+	if !ok || m.counts[status] < limit {
+		return false
+	}
+	m.status = fmt.Sprintf("'%s' is at its WIP limit (%d/%d).", status, m.counts[status], limit)
+	return true
+}
+
+// selectedTask returns the task under the cursor, if any.
+func (m Model) selectedTask() (task.Task, bool) {
+	t, ok := m.list.SelectedItem().(task.Task)
+	return t, ok
+}
+
+// findTask looks up a loaded task by ID.
+func (m Model) findTask(id uint) (task.Task, bool) {
+	for _, item := range m.list.Items() {
+		if t, ok := item.(task.Task); ok && t.ID == id {
+			return t, true
+		}
+	}
+	return task.Task{}, false
+}
+
+// popUndo removes the most recent change from the undo stack and reverts it.
+func (m *Model) popUndo() tea.Cmd {
+	if len(m.undo) == 0 {
+		m.status = "Nothing to undo."
+		return nil
+	}
+	action := m.undo[len(m.undo)-1]
+	m.undo = m.undo[:len(m.undo)-1]
+	tdb := m.db
+	return func() tea.Msg {
+		ctx, cancel := tdb.context()
+		defer cancel()
+		if err := action.revert(ctx, tdb); err != nil {
+			return errMsg{fmt.Errorf("undo %s failed: %w", action.description, err)}
+		}
+		return mutatedMsg{status: "Undid " + action.description + ".", selectID: action.selectID}
+	}
+}
+
+// --- View ---
+
+// listWidth is the width given to the list pane; the detail pane gets the rest.
+func (m Model) listWidth() int {
+	return m.width / 2
+}
+
+func (m Model) View() string {
+	if m.width == 0 {
+		return "loading..."
+	}
+
+	var main string
+	if m.mode == editing {
+		main = paneStyle.Width(m.width - 6).Render(m.form.view())
+	} else {
+		detailWidth := m.width - m.listWidth() - 6
+		main = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(m.listWidth()).Render(m.list.View()),
+			paneStyle.Width(detailWidth).Render(m.detailView()),
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, main, statusStyle.Render(m.status))
+}
+
+// detailView renders every field of the selected task.
+func (m Model) detailView() string {
+	t, ok := m.selectedTask()
+	if !ok {
+		return helpStyle.Render("No task selected. Press a to add one.")
+	}
+
+	project := t.Project
+	if project == "" {
+		project = "-"
+	}
+	tags := "-"
+	if len(t.Tags) > 0 {
+		tags = strings.Join(t.Tags, ", ")
+	}
+	due := task.FormatDue(t.Due)
+	if due == "" {
+		due = "-"
+	}
+	rows := []string{
+		titleStyle.Render(t.Name),
+		"",
+		labelStyle.Render("ID") + fmt.Sprintf("%d", t.ID),
+		labelStyle.Render("Project") + project,
+		labelStyle.Render("Tags") + tags,
+		labelStyle.Render("Due") + due,
+		labelStyle.Render("Status") + t.Status,
+		labelStyle.Render("Created") + t.Created.Local().Format("2006-01-02 15:04"),
+		labelStyle.Render("Updated") + t.Updated.Local().Format("2006-01-02 15:04"),
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/config"
+	"github.com/ashish0kumar/taskly/internal/db"
+)
+
+const (
+	// claimLease is how long a claimed delivery stays hidden from other
+	// dispatchers. It must exceed the time one attempt can take.
+	claimLease = time.Minute
+
+	// claimBatch is how many deliveries are claimed at a time.
+	claimBatch = 20
+)
+
+// Outbox is the queue of deliveries; *db.TaskDB implements it. Exported
+type Outbox interface {
+	ClaimDeliveriesContext(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.Delivery, error)
+	ClaimNewDeliveriesContext(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.Delivery, error)
+	MarkDeliveredContext(ctx context.Context, id int64) error
+	MarkFailedContext(ctx context.Context, id int64, reason string, retryAt time.Time) error
+}
+
+// Options configures a Dispatcher. Zero fields take the defaults shown. Exported
+type Options struct {
+	Client      *http.Client  // An http.Client with a 10s timeout
+	MaxAttempts int           // 10; a delivery failing this often is given up on
+	BaseDelay   time.Duration // 10s; the wait after the first failure, doubling after each
+	MaxDelay    time.Duration // 1h; the longest wait between attempts
+	Now         func() time.Time
+}
+
+// Dispatcher sends queued deliveries. Exported
+type Dispatcher struct {
+	outbox Outbox
+	hooks  []config.Webhook
+	opts   Options
+}
+
+// Result counts what Deliver did. Exported
+type Result struct {
+	Sent    int
+	Failed  int // Will be retried
+	GivenUp int // Failed for the last time
+	Dropped int // For webhooks no longer in the config
+}
+
+// NewDispatcher returns a Dispatcher sending outbox deliveries to hooks.
+// Exported
+func NewDispatcher(outbox Outbox, hooks []config.Webhook, opts Options) *Dispatcher {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 10 * time.Second
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = time.Hour
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Dispatcher{outbox: outbox, hooks: hooks, opts: opts}
+}
+
+// hook returns the configured webhook a delivery is for. Deliveries name
+// their webhook by its position in the config, which identifies it even
+// when several share a URL; if the config has changed since and that
+// position now holds another URL, the first webhook with the delivery's
+// URL is used instead.
+func (d *Dispatcher) hook(delivery db.Delivery) (config.Webhook, bool) {
+	if i := delivery.Hook; i >= 0 && i < len(d.hooks) && d.hooks[i].URL == delivery.URL {
+		return d.hooks[i], true
+	}
+	for _, hook := range d.hooks {
+		if hook.URL == delivery.URL {
+			return hook, true
+		}
+	}
+	return config.Webhook{}, false
+}
+
+// Deliver sends every delivery that is due. Failed deliveries are
+// rescheduled rather than reported as errors; the error is for problems
+// with the outbox itself, or ctx ending. Exported
+func (d *Dispatcher) Deliver(ctx context.Context) (Result, error) {
+	return d.deliver(ctx, d.outbox.ClaimDeliveriesContext)
+}
+
+// DeliverNew is like Deliver but only sends deliveries that have not been
+// attempted yet, such as those for the changes a command just made, so an
+// unreachable webhook's backlog of retries cannot hold it up. Exported
+func (d *Dispatcher) DeliverNew(ctx context.Context) (Result, error) {
+	return d.deliver(ctx, d.outbox.ClaimNewDeliveriesContext)
+}
+
+// deliver sends the deliveries claim returns, in batches, until none are left.
+func (d *Dispatcher) deliver(ctx context.Context, claim func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.Delivery, error)) (Result, error) {
+	var result Result
+	for {
+		due, err := claim(ctx, d.opts.Now(), claimLease, claimBatch)
+		if err != nil {
+			return result, err
+		}
+		for _, delivery := range due {
+			if err := d.attempt(ctx, delivery, &result); err != nil {
+				return result, err
+			}
+		}
+		if len(due) < claimBatch {
+			return result, nil
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome.
+func (d *Dispatcher) attempt(ctx context.Context, delivery db.Delivery, result *Result) error {
+	hook, ok := d.hook(delivery)
+	if !ok {
+		result.Dropped++
+		return d.outbox.MarkDeliveredContext(ctx, delivery.ID)
+	}
+
+	sendErr := d.send(ctx, hook, delivery)
+	if ctx.Err() != nil {
+		// Leave the delivery claimed; it is retried when the lease ends.
+		return ctx.Err()
+	}
+	if sendErr == nil {
+		result.Sent++
+		return d.outbox.MarkDeliveredContext(ctx, delivery.ID)
+	}
+
+	attempts := delivery.Attempts + 1
+	var retryAt time.Time
+	if attempts < d.opts.MaxAttempts {
+		retryAt = d.opts.Now().Add(d.backoff(attempts))
+		result.Failed++
+	} else {
+		result.GivenUp++
+	}
+	return d.outbox.MarkFailedContext(ctx, delivery.ID, sendErr.Error(), retryAt)
+}
+
+// send POSTs a delivery, succeeding on any 2xx response.
+func (d *Dispatcher) send(ctx context.Context, hook config.Webhook, delivery db.Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "taskly-webhook")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, delivery.Payload))
+	}
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return nil
+}
+
+// backoff returns the wait after a delivery failed attempts times.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.BaseDelay
+	for i := 1; i < attempts && delay < d.opts.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.opts.MaxDelay {
+		delay = d.opts.MaxDelay
+	}
+	return delay
+}
+
+// Run calls Deliver every interval until ctx is done, for long-running
+// commands. Outbox errors are retried at the next interval. It returns
+// ctx.Err(). Exported
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.Deliver(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
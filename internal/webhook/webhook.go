@@ -0,0 +1,117 @@
+// Package webhook tells the webhooks in the config file about task changes.
+//
+// Changes are queued in the database's outbox in the same transaction that
+// makes them (see Router and db.TaskDB.SetRouter), so a delivery is never
+// lost when the process exits before sending it, and a Dispatcher sends
+// them, retrying failures with exponential backoff.
+//
+// Each delivery is a POST of a JSON Payload with these headers:
+//
+//	X-Taskly-Event:     created, updated or deleted
+//	X-Taskly-Delivery:  an ID that stays the same across retries
+//	X-Taskly-Signature: sha256=<hex HMAC-SHA256 of the body keyed with the secret>
+//
+// The signature is only sent for webhooks with a secret; see Verify.
+// Deliveries may arrive more than once and out of order, so receivers
+// should use the delivery ID to ignore repeats.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/config"
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/exporter"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Headers set on each delivery. Exported
+const (
+	EventHeader     = "X-Taskly-Event"
+	DeliveryHeader  = "X-Taskly-Delivery"
+	SignatureHeader = "X-Taskly-Signature"
+)
+
+// Payload is the JSON body of a delivery. Exported
+type Payload struct {
+	Event    task.ChangeType   `json:"event"`
+	Occurred time.Time         `json:"occurred"`
+	Task     exporter.JSONTask `json:"task"` // After the change; before it for deleted
+
+	// Previous is the task before an update.
+	Previous *exporter.JSONTask `json:"previous,omitempty"`
+}
+
+// Router returns a db.Router queuing a delivery to each of hooks whose
+// filters match a change. Exported
+func Router(hooks []config.Webhook) db.Router {
+	return func(c task.Change) ([]db.OutboxEntry, error) {
+		var entries []db.OutboxEntry
+		var body []byte
+		for i, hook := range hooks {
+			if !Matches(hook, c) {
+				continue
+			}
+			if body == nil {
+				var err error
+				if body, err = json.Marshal(newPayload(c, time.Now().UTC())); err != nil {
+					return nil, err
+				}
+			}
+			entries = append(entries, db.OutboxEntry{Hook: i, URL: hook.URL, Event: c.Type, Payload: body})
+		}
+		return entries, nil
+	}
+}
+
+// newPayload describes c as it happened at occurred.
+func newPayload(c task.Change, occurred time.Time) Payload {
+	p := Payload{Event: c.Type, Occurred: occurred, Task: exporter.ToJSON(c.Task)}
+	if c.Type == task.Updated {
+		previous := exporter.ToJSON(c.Previous)
+		p.Previous = &previous
+	}
+	return p
+}
+
+// Matches reports whether hook's filters select change c. Exported
+func Matches(hook config.Webhook, c task.Change) bool {
+	if len(hook.Events) > 0 {
+		found := false
+		for _, event := range hook.Events {
+			found = found || task.ChangeType(event) == c.Type
+		}
+		if !found {
+			return false
+		}
+	}
+	if hook.Project != "" && c.Task.Project != hook.Project {
+		return false
+	}
+	if hook.Status != "" {
+		if c.Task.Status != hook.Status {
+			return false
+		}
+		if c.Type == task.Updated && c.Previous.Status == hook.Status {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign returns the X-Taskly-Signature value for body. Exported
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature, the X-Taskly-Signature header of a
+// delivery, is valid for body and secret. Exported
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, body)))
+}
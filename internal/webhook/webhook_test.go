@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/config"
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// receiver records the deliveries posted to it, answering with status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+// openOutbox returns a TaskDB queuing deliveries to hooks.
+func openOutbox(t *testing.T, hooks []config.Webhook) *db.TaskDB {
+	t.Helper()
+	tdb, err := db.OpenPath(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("OpenPath: %v", err)
+	}
+	t.Cleanup(func() { tdb.Close() })
+	tdb.SetRouter(Router(hooks))
+	return tdb
+}
+
+func TestDeliver(t *testing.T) {
+	rc := &receiver{status: http.StatusNoContent}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	hook := config.Webhook{URL: srv.URL, Secret: "s3cret", Events: []string{"created", "updated"}}
+	tdb := openOutbox(t, []config.Webhook{hook})
+	ctx := context.Background()
+
+	created, err := tdb.InsertContext(ctx, "write tests", "taskly")
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	done := task.Done.String()
+	if _, err := tdb.UpdateContext(ctx, created.ID, db.Changes{Status: &done}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := tdb.DeleteContext(ctx, created.ID); err != nil { // Not subscribed to
+		t.Fatalf("Delete: %v", err)
+	}
+
+	result, err := NewDispatcher(tdb, []config.Webhook{hook}, Options{}).Deliver(ctx)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if result != (Result{Sent: 2}) {
+		t.Fatalf("result = %+v, want 2 sent", result)
+	}
+	if len(rc.requests) != 2 {
+		t.Fatalf("received %d deliveries, want 2", len(rc.requests))
+	}
+
+	for i, want := range []task.ChangeType{task.Created, task.Updated} {
+		r, body := rc.requests[i], rc.bodies[i]
+		if got := r.Header.Get(EventHeader); got != string(want) {
+			t.Errorf("delivery %d: %s = %q, want %q", i, EventHeader, got, want)
+		}
+		if r.Header.Get(DeliveryHeader) == "" {
+			t.Errorf("delivery %d: no %s", i, DeliveryHeader)
+		}
+		if !Verify(hook.Secret, body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("delivery %d: bad signature %q", i, r.Header.Get(SignatureHeader))
+		}
+		var p Payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatalf("delivery %d: %v", i, err)
+		}
+		if p.Event != want || p.Task.ID != created.ID {
+			t.Errorf("delivery %d: payload %+v, want %s of task %d", i, p, want, created.ID)
+		}
+	}
+
+	var update Payload
+	json.Unmarshal(rc.bodies[1], &update)
+	if update.Task.Status != done || update.Previous == nil || update.Previous.Status != task.Todo.String() {
+		t.Errorf("update payload = %+v, want todo -> done", update)
+	}
+
+	if queued, _ := tdb.DeliveriesContext(ctx); len(queued) != 0 {
+		t.Errorf("%d deliveries still queued after sending", len(queued))
+	}
+}
+
+func TestRetries(t *testing.T) {
+	rc := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	hook := config.Webhook{URL: srv.URL}
+	tdb := openOutbox(t, []config.Webhook{hook})
+	ctx := context.Background()
+	if _, err := tdb.InsertContext(ctx, "flaky", ""); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	now := time.Now()
+	d := NewDispatcher(tdb, []config.Webhook{hook}, Options{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		Now:         func() time.Time { return now },
+	})
+
+	// Each failure doubles the wait, and nothing is sent before it ends.
+	for i, wait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		result, err := d.Deliver(ctx)
+		if err != nil {
+			t.Fatalf("Deliver: %v", err)
+		}
+		if result != (Result{Failed: 1}) {
+			t.Fatalf("attempt %d: result = %+v, want 1 failed", i+1, result)
+		}
+		queued, _ := tdb.DeliveriesContext(ctx)
+		if len(queued) != 1 || queued[0].Attempts != i+1 || !queued[0].NextAttempt.Equal(now.Add(wait).UTC()) {
+			t.Fatalf("attempt %d: queued = %+v, want a retry in %s", i+1, queued, wait)
+		}
+		if result, _ := d.Deliver(ctx); result != (Result{}) {
+			t.Fatalf("attempt %d: sent again before the retry was due: %+v", i+1, result)
+		}
+		now = now.Add(wait)
+	}
+
+	result, err := d.Deliver(ctx)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if result != (Result{GivenUp: 1}) {
+		t.Fatalf("result = %+v, want 1 given up", result)
+	}
+	queued, _ := tdb.DeliveriesContext(ctx)
+	if len(queued) != 1 || !queued[0].NextAttempt.IsZero() || queued[0].LastError == "" {
+		t.Fatalf("queued = %+v, want a delivery given up on with its error", queued)
+	}
+
+	// Retrying it lets it through once the receiver recovers.
+	if n, err := tdb.RetryFailedContext(ctx); err != nil || n != 1 {
+		t.Fatalf("RetryFailed = %d, %v; want 1", n, err)
+	}
+	rc.mu.Lock()
+	rc.status = http.StatusOK
+	rc.mu.Unlock()
+	now = time.Now()
+	if result, _ := d.Deliver(ctx); result != (Result{Sent: 1}) {
+		t.Fatalf("result after retry = %+v, want 1 sent", result)
+	}
+}
+
+func TestDropRemovedWebhook(t *testing.T) {
+	tdb := openOutbox(t, []config.Webhook{{URL: "http://127.0.0.1:1/gone"}})
+	ctx := context.Background()
+	if _, err := tdb.InsertContext(ctx, "orphan", ""); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	result, err := NewDispatcher(tdb, nil, Options{}).Deliver(ctx)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if result != (Result{Dropped: 1}) {
+		t.Fatalf("result = %+v, want 1 dropped", result)
+	}
+	if queued, _ := tdb.DeliveriesContext(ctx); len(queued) != 0 {
+		t.Errorf("%d deliveries still queued", len(queued))
+	}
+}
+
+func TestMatches(t *testing.T) {
+	todo := task.Task{ID: 1, Project: "work", Status: task.Todo.String()}
+	done := task.Task{ID: 1, Project: "work", Status: task.Done.String()}
+
+	tests := []struct {
+		name string
+		hook config.Webhook
+		c    task.Change
+		want bool
+	}{
+		{"no filters", config.Webhook{}, task.Change{Type: task.Deleted, Task: todo}, true},
+		{"event listed", config.Webhook{Events: []string{"deleted"}}, task.Change{Type: task.Deleted, Task: todo}, true},
+		{"event not listed", config.Webhook{Events: []string{"created"}}, task.Change{Type: task.Updated, Task: done, Previous: todo}, false},
+		{"project", config.Webhook{Project: "work"}, task.Change{Type: task.Created, Task: todo}, true},
+		{"other project", config.Webhook{Project: "home"}, task.Change{Type: task.Created, Task: todo}, false},
+		{"changed to status", config.Webhook{Status: "done"}, task.Change{Type: task.Updated, Task: done, Previous: todo}, true},
+		{"already in status", config.Webhook{Status: "done"}, task.Change{Type: task.Updated, Task: done, Previous: done}, false},
+		{"other status", config.Webhook{Status: "done"}, task.Change{Type: task.Created, Task: todo}, false},
+		{"created in status", config.Webhook{Status: "todo"}, task.Change{Type: task.Created, Task: todo}, true},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.hook, tt.c); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"created"}`)
+	sig := Sign("key", body)
+	if !Verify("key", body, sig) {
+		t.Error("Verify rejected a valid signature")
+	}
+	if Verify("other", body, sig) || Verify("key", []byte(`{}`), sig) {
+		t.Error("Verify accepted a signature for another secret or body")
+	}
+}
+
+func TestDeliverSharedURL(t *testing.T) {
+	rc := &receiver{status: http.StatusOK}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	// Two webhooks on one receiver, each with its own secret and filter.
+	hooks := []config.Webhook{
+		{URL: srv.URL, Secret: "work", Project: "work"},
+		{URL: srv.URL, Secret: "home", Project: "home"},
+	}
+	tdb := openOutbox(t, hooks)
+	ctx := context.Background()
+	for _, project := range []string{"work", "home"} {
+		if _, err := tdb.InsertContext(ctx, "task", project); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+
+	if _, err := NewDispatcher(tdb, hooks, Options{}).Deliver(ctx); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if len(rc.requests) != 2 {
+		t.Fatalf("received %d deliveries, want 2", len(rc.requests))
+	}
+	for i, body := range rc.bodies {
+		var p Payload
+		json.Unmarshal(body, &p)
+		if secret := p.Task.Project; !Verify(secret, body, rc.requests[i].Header.Get(SignatureHeader)) {
+			t.Errorf("delivery for %s is not signed with that webhook's secret", p.Task.Project)
+		}
+	}
+}
+
+func TestDeliverNewSkipsRetries(t *testing.T) {
+	rc := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	hook := config.Webhook{URL: srv.URL}
+	tdb := openOutbox(t, []config.Webhook{hook})
+	ctx := context.Background()
+	if _, err := tdb.InsertContext(ctx, "failed before", ""); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	now := time.Now()
+	d := NewDispatcher(tdb, []config.Webhook{hook}, Options{BaseDelay: time.Minute, Now: func() time.Time { return now }})
+	if result, _ := d.DeliverNew(ctx); result != (Result{Failed: 1}) {
+		t.Fatalf("first attempt: result = %+v, want 1 failed", result)
+	}
+
+	// Once its retry is due, only Deliver sends it; DeliverNew sends just
+	// the delivery for the new change.
+	now = now.Add(time.Hour)
+	if _, err := tdb.InsertContext(ctx, "new", ""); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if result, _ := d.DeliverNew(ctx); result != (Result{Failed: 1}) {
+		t.Fatalf("DeliverNew: result = %+v, want only the new delivery", result)
+	}
+	if len(rc.requests) != 2 {
+		t.Fatalf("received %d requests, want 2", len(rc.requests))
+	}
+	if result, _ := d.Deliver(ctx); result != (Result{Failed: 1}) {
+		t.Fatalf("Deliver: result = %+v, want the retry of the first delivery", result)
+	}
+}
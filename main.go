@@ -13,6 +13,6 @@ func main() {
 	if err := cmd.Execute(); err != nil {
 		// Print errors returned by commands or setup to stderr.
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,91 @@
+package taskly
+
+import (
+	"context"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// EventType says what happened to a task.
+type EventType string
+
+// The kinds of change Subscribe reports.
+const (
+	Created = EventType(task.Created)
+	Updated = EventType(task.Updated)
+	Deleted = EventType(task.Deleted)
+)
+
+// Event reports one change to a task. For Deleted events, Task is the last
+// state Subscribe saw.
+type Event struct {
+	Type EventType
+	Task Task
+}
+
+// Subscribe calls fn for each task created, updated or deleted until ctx is
+// done, whether the change was made through c, another Client or the taskly
+// command. The store is checked every poll interval (see
+// Options.PollInterval), so changes within one interval are coalesced: a
+// task added and removed again between two checks is not reported. Events
+// from one check are delivered in task ID order, and fn runs on the calling
+// goroutine, so a slow fn delays the next check.
+//
+// Subscribe returns ctx.Err() once ctx is done, or the first error from the
+// store.
+func (c *Client) Subscribe(ctx context.Context, fn func(Event)) error {
+	// Start watching before taking the first snapshot, so nothing that
+	// happens in between is missed.
+	watcher, err := c.store.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	writes := c.writes.Load()
+	prev, err := c.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// Watchers only see other processes' writes to the SQLite
+		// database, so count this client's own writes too.
+		changed, err := watcher.Changed()
+		if err != nil {
+			return err
+		}
+		if w := c.writes.Load(); w != writes {
+			writes, changed = w, true
+		}
+		if !changed {
+			continue
+		}
+
+		next, err := c.snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		for _, change := range task.Diff(prev, next) {
+			fn(Event{Type: EventType(change.Type), Task: fromTask(change.Task)})
+		}
+		prev = next
+	}
+}
+
+// snapshot returns every task keyed by ID.
+func (c *Client) snapshot(ctx context.Context) (map[uint]task.Task, error) {
+	tasks, err := c.store.GetTasksContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return task.Snapshot(tasks), nil
+}
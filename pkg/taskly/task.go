@@ -0,0 +1,145 @@
+package taskly
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/task"
+)
+
+// Status is where a task is in the todo, in progress, done workflow.
+type Status string
+
+// The statuses a task can have.
+const (
+	Todo       Status = "todo"
+	InProgress Status = "in progress"
+	Done       Status = "done"
+)
+
+// Statuses returns every status in workflow order.
+func Statuses() []Status {
+	return []Status{Todo, InProgress, Done}
+}
+
+// Task is a single task.
+type Task struct {
+	ID        uint
+	Name      string
+	Project   string // Empty when the task has no project
+	Status    Status
+	Created   time.Time
+	Updated   time.Time // Last modification; equals Created for untouched tasks
+	Completed time.Time // When the task was marked done; zero unless Status is Done
+	Position  float64   // Manual rank; lower values come first within a status
+	Tags      []string  // Sorted and lower-case
+	Due       time.Time // Due date at midnight UTC; zero when there is none
+}
+
+// NewTask describes a task for Client.Add.
+type NewTask struct {
+	Name    string // Required
+	Project string
+	Status  Status    // Defaults to Todo
+	Tags    []string  // Lower-cased; may not contain spaces or commas
+	Due     time.Time // Only the date, in the time's location, is kept
+}
+
+// Changes lists the fields Client.Update sets; nil fields are left alone.
+type Changes struct {
+	Name    *string
+	Project *string
+	Status  *Status
+	Tags    *[]string  // Replaces all tags; an empty list removes them
+	Due     *time.Time // Sets the due date; the zero time removes it
+}
+
+// Filter selects and orders the tasks returned by Client.List. The zero
+// Filter returns every task.
+type Filter struct {
+	Project   string    // Only tasks in this project
+	Tag       string    // Only tasks with this tag
+	Statuses  []Status  // Only tasks in one of these statuses
+	HideDone  bool      // Leave out tasks that are done
+	DoneSince time.Time // Leave out done tasks completed before this
+	Sort      []SortKey // Defaults to manual rank within status
+}
+
+// SortKey orders tasks by one field. Valid fields are listed by SortFields.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SortFields returns the field names SortKey accepts.
+func SortFields() []string {
+	return db.SortFields()
+}
+
+// ParseSort parses a comma-separated sort order such as
+// "status,created:desc", as accepted by 'taskly list --sort'.
+func ParseSort(spec string) ([]SortKey, error) {
+	keys, err := db.ParseSortKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+	sort := make([]SortKey, len(keys))
+	for i, k := range keys {
+		sort[i] = SortKey{Field: k.Field, Desc: k.Desc}
+	}
+	return sort, nil
+}
+
+// listOptions converts f for the store, validating statuses and sort fields.
+func (f Filter) listOptions() (db.ListOptions, error) {
+	opts := db.ListOptions{Project: f.Project, Tag: strings.ToLower(f.Tag), HideDone: f.HideDone, DoneSince: f.DoneSince}
+	for _, s := range f.Statuses {
+		if _, err := task.ParseStatus(string(s)); err != nil {
+			return opts, db.Errorf(ErrInvalidInput, "%w", err)
+		}
+		opts.Statuses = append(opts.Statuses, string(s))
+	}
+	for _, k := range f.Sort {
+		if !validSortField(k.Field) {
+			return opts, db.Errorf(ErrInvalidInput, "unknown sort field %q", k.Field)
+		}
+		opts.Sort = append(opts.Sort, db.SortKey{Field: k.Field, Desc: k.Desc})
+	}
+	return opts, nil
+}
+
+// validSortField reports whether field is one of SortFields.
+func validSortField(field string) bool {
+	for _, f := range db.SortFields() {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fromTask converts a stored task to the public type.
+func fromTask(t task.Task) Task {
+	return Task{
+		ID:        t.ID,
+		Name:      t.Name,
+		Project:   t.Project,
+		Status:    Status(t.Status),
+		Created:   t.Created,
+		Updated:   t.Updated,
+		Completed: t.Completed,
+		Position:  t.Position,
+		Tags:      t.Tags,
+		Due:       t.Due,
+	}
+}
+
+// fromTasks converts a slice of stored tasks.
+func fromTasks(tasks []task.Task) []Task {
+	out := make([]Task, len(tasks))
+	for i, t := range tasks {
+		out[i] = fromTask(t)
+	}
+	return out
+}
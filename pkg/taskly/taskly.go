@@ -0,0 +1,219 @@
+// Package taskly is the Go API for reading and changing the tasks managed by
+// the taskly command, so bots and integrations can work on the same data.
+//
+// Open a Client on the user's configured store with OpenDefault, on a
+// specific SQLite file or task directory with Open, or on a throwaway
+// in-memory store with OpenMemory:
+//
+//	client, err := taskly.OpenDefault()
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	t, err := client.Add(ctx, taskly.NewTask{Name: "Write release notes", Project: "v2"})
+//	open, err := client.List(ctx, taskly.Filter{Project: "v2", HideDone: true})
+//
+// # Compatibility
+//
+// This package follows semantic versioning: within a major version,
+// exported identifiers are neither removed nor changed incompatibly, and
+// new fields are only added to structs in ways that keep keyed composite
+// literals compiling. Packages under internal/ carry no such guarantee and
+// must not be imported.
+package taskly
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/config"
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/internal/filestore"
+	"github.com/ashish0kumar/taskly/internal/memstore"
+	"github.com/ashish0kumar/taskly/internal/task"
+	"github.com/ashish0kumar/taskly/internal/webhook"
+)
+
+// Errors returned by Client methods wrap one of these when the cause is
+// known; test for them with errors.Is.
+var (
+	ErrNotFound     = db.ErrNotFound     // No task has the given ID
+	ErrConflict     = db.ErrConflict     // The change clashes with existing data
+	ErrInvalidInput = db.ErrInvalidInput // A name, status, filter or option is not valid
+	ErrBusy         = db.ErrBusy         // Another process kept the SQLite database locked
+)
+
+// Storage backends accepted in Options.Backend.
+const (
+	BackendSQLite = config.BackendSQLite // A SQLite database file
+	BackendFiles  = config.BackendFiles  // One Markdown file per task in a .taskly directory
+)
+
+// DefaultPollInterval is how often Subscribe checks for changes unless
+// Options.PollInterval says otherwise.
+const DefaultPollInterval = time.Second
+
+// Options selects the store a Client opens.
+type Options struct {
+	// Backend is BackendSQLite (the default when empty) or BackendFiles.
+	Backend string
+
+	// Path is the SQLite database file. Empty means the database the
+	// taskly command uses, as printed by 'taskly where'.
+	Path string
+
+	// Dir is the .taskly directory holding task files for BackendFiles.
+	// Empty means the nearest .taskly directory above the working
+	// directory, as the taskly command finds it.
+	Dir string
+
+	// PollInterval is how often Subscribe checks for changes. Zero means
+	// DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// Client reads and changes tasks in one store. It is safe for concurrent
+// use by multiple goroutines.
+type Client struct {
+	store        db.Store
+	pollInterval time.Duration
+	writes       atomic.Int64 // Counts changes made through this client
+}
+
+// Open opens the store described by opts.
+func Open(opts Options) (*Client, error) {
+	var store db.Store
+	switch opts.Backend {
+	case "", BackendSQLite:
+		var tdb *db.TaskDB
+		var err error
+		if opts.Path == "" {
+			tdb, err = db.OpenDB()
+		} else {
+			tdb, err = db.OpenPath(opts.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		store = tdb
+	case BackendFiles:
+		dir := opts.Dir
+		if dir == "" {
+			var err error
+			if dir, err = filestore.FindDir("."); err != nil {
+				return nil, fmt.Errorf("could not locate task directory: %w", err)
+			}
+		}
+		fs, err := filestore.Open(dir)
+		if err != nil {
+			return nil, err
+		}
+		store = fs
+	default:
+		return nil, db.Errorf(ErrInvalidInput, "%w", config.ValidateBackend(opts.Backend))
+	}
+	return newClient(store, opts.PollInterval), nil
+}
+
+// OpenDefault opens the store the taskly command would use, honouring the
+// "backend" and "webhooks" settings in the user's config file.
+//
+// Changes made through the Client are queued for the configured webhooks,
+// as the taskly command's are, but the Client never sends them itself: the
+// next taskly command that changes a task, 'taskly webhooks' or 'taskly
+// serve' does. Clients from Open and OpenMemory queue nothing.
+func OpenDefault() (*Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	c, err := Open(Options{Backend: cfg.Backend})
+	if err != nil {
+		return nil, err
+	}
+	if tdb, ok := c.store.(*db.TaskDB); ok && len(cfg.Webhooks) > 0 {
+		tdb.SetRouter(webhook.Router(cfg.Webhooks))
+	}
+	return c, nil
+}
+
+// OpenMemory returns a Client on an empty store that lives only as long as
+// the Client, which is handy for tests.
+func OpenMemory() *Client {
+	return newClient(memstore.New(), 0)
+}
+
+// newClient wraps store, defaulting the poll interval.
+func newClient(store db.Store, pollInterval time.Duration) *Client {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &Client{store: store, pollInterval: pollInterval}
+}
+
+// Close releases the store. The Client must not be used afterwards.
+func (c *Client) Close() error {
+	return c.store.Close()
+}
+
+// List returns the tasks matching f, ordered by f.Sort or, by default, by
+// manual rank within status as 'taskly list' shows them.
+func (c *Client) List(ctx context.Context, f Filter) ([]Task, error) {
+	opts, err := f.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.store.ListTasksContext(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return fromTasks(tasks), nil
+}
+
+// Get returns the task with the given ID.
+func (c *Client) Get(ctx context.Context, id uint) (Task, error) {
+	t, err := c.store.GetTaskContext(ctx, id)
+	if err != nil {
+		return Task{}, err
+	}
+	return fromTask(t), nil
+}
+
+// Add creates a task and returns it with its ID and timestamps set.
+func (c *Client) Add(ctx context.Context, n NewTask) (Task, error) {
+	t, err := c.store.InsertTaskContext(ctx, task.Task{Name: n.Name, Project: n.Project, Status: string(n.Status), Tags: n.Tags, Due: n.Due})
+	if err != nil {
+		return Task{}, err
+	}
+	c.writes.Add(1)
+	return fromTask(t), nil
+}
+
+// Update applies the non-nil fields of ch to the task with the given ID and
+// returns the result. Moving a task into or out of Done sets or clears its
+// completion time.
+func (c *Client) Update(ctx context.Context, id uint, ch Changes) (Task, error) {
+	var status *string
+	if ch.Status != nil {
+		s := string(*ch.Status)
+		status = &s
+	}
+	t, err := c.store.UpdateContext(ctx, id, db.Changes{Name: ch.Name, Project: ch.Project, Status: status, Tags: ch.Tags, Due: ch.Due})
+	if err != nil {
+		return Task{}, err
+	}
+	c.writes.Add(1)
+	return fromTask(t), nil
+}
+
+// Delete removes the task with the given ID.
+func (c *Client) Delete(ctx context.Context, id uint) error {
+	if err := c.store.DeleteContext(ctx, id); err != nil {
+		return err
+	}
+	c.writes.Add(1)
+	return nil
+}
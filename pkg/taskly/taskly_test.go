@@ -0,0 +1,228 @@
+package taskly_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashish0kumar/taskly/internal/db"
+	"github.com/ashish0kumar/taskly/pkg/taskly"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c := taskly.OpenMemory()
+	defer c.Close()
+
+	a, err := c.Add(ctx, taskly.NewTask{Name: "Write docs", Project: "lib"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if a.ID == 0 || a.Status != taskly.Todo || a.Created.IsZero() {
+		t.Errorf("Add returned %+v", a)
+	}
+	if _, err := c.Add(ctx, taskly.NewTask{Name: "Ship", Project: "lib", Status: taskly.Done}); err != nil {
+		t.Fatalf("Add done: %v", err)
+	}
+	if _, err := c.Add(ctx, taskly.NewTask{Name: "Elsewhere"}); err != nil {
+		t.Fatalf("Add without project: %v", err)
+	}
+
+	status := taskly.InProgress
+	a, err = c.Update(ctx, a.ID, taskly.Changes{Status: &status})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, err := c.Get(ctx, a.ID); err != nil || got.Status != taskly.InProgress {
+		t.Errorf("Get after Update = %+v, %v", got, err)
+	}
+
+	open, err := c.List(ctx, taskly.Filter{Project: "lib", HideDone: true})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(open) != 1 || open[0].ID != a.ID {
+		t.Errorf("List(lib, hide done) = %+v", open)
+	}
+	done, err := c.List(ctx, taskly.Filter{Statuses: []taskly.Status{taskly.Done}})
+	if err != nil {
+		t.Fatalf("List by status: %v", err)
+	}
+	if len(done) != 1 || done[0].Name != "Ship" || done[0].Completed.IsZero() {
+		t.Errorf("List(done) = %+v", done)
+	}
+	sort, err := taskly.ParseSort("name:desc")
+	if err != nil {
+		t.Fatalf("ParseSort: %v", err)
+	}
+	sorted, err := c.List(ctx, taskly.Filter{Sort: sort})
+	if err != nil || len(sorted) != 3 || sorted[0].Name != "Write docs" {
+		t.Errorf("List sorted by name desc = %+v, %v", sorted, err)
+	}
+
+	if err := c.Delete(ctx, a.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := c.Get(ctx, a.ID); !errors.Is(err, taskly.ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	c := taskly.OpenMemory()
+	defer c.Close()
+
+	if _, err := c.Add(ctx, taskly.NewTask{}); !errors.Is(err, taskly.ErrInvalidInput) {
+		t.Errorf("Add without a name: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := c.List(ctx, taskly.Filter{Statuses: []taskly.Status{"later"}}); !errors.Is(err, taskly.ErrInvalidInput) {
+		t.Errorf("List with an unknown status: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := c.List(ctx, taskly.Filter{Sort: []taskly.SortKey{{Field: "size"}}}); !errors.Is(err, taskly.ErrInvalidInput) {
+		t.Errorf("List with an unknown sort field: err = %v, want ErrInvalidInput", err)
+	}
+	if err := c.Delete(ctx, 42); !errors.Is(err, taskly.ErrNotFound) {
+		t.Errorf("Delete of a missing task: err = %v, want ErrNotFound", err)
+	}
+	if _, err := taskly.Open(taskly.Options{Backend: "cloud"}); !errors.Is(err, taskly.ErrInvalidInput) {
+		t.Errorf("Open with an unknown backend: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	opts := taskly.Options{Path: path, PollInterval: 10 * time.Millisecond}
+	watching, err := taskly.Open(opts)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer watching.Close()
+	other, err := taskly.Open(opts)
+	if err != nil {
+		t.Fatalf("Open second client: %v", err)
+	}
+	defer other.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := make(chan taskly.Event, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watching.Subscribe(ctx, func(e taskly.Event) { events <- e })
+	}()
+	next := func(want taskly.EventType) taskly.Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Type != want {
+				t.Fatalf("event %+v, want %s", e, want)
+			}
+			return e
+		case err := <-errc:
+			t.Fatalf("Subscribe returned early: %v", err)
+		case <-ctx.Done():
+			t.Fatalf("no %s event", want)
+		}
+		return taskly.Event{}
+	}
+	time.Sleep(50 * time.Millisecond) // Let Subscribe take its first snapshot
+
+	// A change from another connection, as another process would make.
+	added, err := other.Add(ctx, taskly.NewTask{Name: "From elsewhere"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if e := next(taskly.Created); e.Task.ID != added.ID {
+		t.Errorf("created event for task %d, want %d", e.Task.ID, added.ID)
+	}
+
+	// Changes through the subscribed client itself.
+	name := "Renamed"
+	if _, err := watching.Update(ctx, added.ID, taskly.Changes{Name: &name}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if e := next(taskly.Updated); e.Task.Name != name {
+		t.Errorf("updated event has name %q, want %q", e.Task.Name, name)
+	}
+	if err := watching.Delete(ctx, added.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if e := next(taskly.Deleted); e.Task.ID != added.ID {
+		t.Errorf("deleted event for task %d, want %d", e.Task.ID, added.ID)
+	}
+
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Subscribe returned %v after cancel, want context.Canceled", err)
+	}
+}
+
+func Example() {
+	ctx := context.Background()
+	client := taskly.OpenMemory() // Use taskly.OpenDefault() for the user's tasks
+	defer client.Close()
+
+	client.Add(ctx, taskly.NewTask{Name: "Draft announcement", Project: "launch"})
+	client.Add(ctx, taskly.NewTask{Name: "Book venue", Project: "launch", Status: taskly.Done})
+
+	open, err := client.List(ctx, taskly.Filter{Project: "launch", HideDone: true})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, t := range open {
+		fmt.Printf("%d %s (%s)\n", t.ID, t.Name, t.Status)
+	}
+	// Output: 1 Draft announcement (todo)
+}
+
+func TestOpenDefaultQueuesWebhooks(t *testing.T) {
+	received := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { received <- struct{}{} }))
+	defer srv.Close()
+
+	cfgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfgHome)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Join(cfgHome, "taskly"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := fmt.Sprintf(`{"webhooks": [{"url": %q}]}`, srv.URL)
+	if err := os.WriteFile(filepath.Join(cfgHome, "taskly", "config.json"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := taskly.OpenDefault()
+	if err != nil {
+		t.Fatalf("OpenDefault: %v", err)
+	}
+	if _, err := client.Add(context.Background(), taskly.NewTask{Name: "Tell the hook"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	client.Close()
+
+	tdb, err := db.OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer tdb.Close()
+	queued, err := tdb.DeliveriesContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 1 || queued[0].URL != srv.URL || queued[0].Event != "created" {
+		t.Errorf("queued deliveries = %+v, want one created event for %s", queued, srv.URL)
+	}
+	select {
+	case <-received:
+		t.Error("the client sent the webhook itself")
+	default:
+	}
+}